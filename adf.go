@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// adfNode is a node in an Atlassian Document Format document.
+// Jira Cloud can hand us comments in this format instead of text.
+type adfNode struct {
+	Type    string                 `json:"type"`
+	Text    string                 `json:"text"`
+	Attrs   map[string]interface{} `json:"attrs"`
+	Content []adfNode              `json:"content"`
+}
+
+func writeADF(b *strings.Builder, node adfNode) {
+	switch node.Type {
+	case "text":
+		b.WriteString(node.Text)
+	case "hardBreak":
+		b.WriteString("\n")
+	case "listItem":
+		b.WriteString("- ")
+	case "mention", "emoji":
+		if text, ok := node.Attrs["text"].(string); ok {
+			b.WriteString(text)
+		}
+	}
+
+	for _, child := range node.Content {
+		writeADF(b, child)
+	}
+
+	switch node.Type {
+	case "paragraph", "heading", "codeBlock", "blockquote", "listItem":
+		b.WriteString("\n")
+	}
+}
+
+// commentText returns the plain text of a worklog comment. REST v3
+// sends an ADF document, which is flattened to text, while REST v2 on
+// Jira Server and Data Center sends a plain string.
+func commentText(raw json.RawMessage) string {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return ""
+	}
+
+	if trimmed[0] == '"' {
+		var text string
+		if err := json.Unmarshal(trimmed, &text); err != nil {
+			return ""
+		}
+		return text
+	}
+
+	var doc adfNode
+	err := json.Unmarshal(trimmed, &doc)
+	if err != nil || doc.Type != "doc" {
+		return ""
+	}
+
+	var b strings.Builder
+	writeADF(&b, doc)
+	return strings.TrimSpace(b.String())
+}
+
+// worklogRecord is a worklog as REST v3 or v2 returns it. The comment
+// is kept raw until we know if it is an ADF document or a string.
+type worklogRecord struct {
+	jira.WorklogRecord
+	Comment json.RawMessage `json:"comment,omitempty"`
+}
+
+// record is the go-jira worklog with the comment as plain text
+func (w worklogRecord) record() jira.WorklogRecord {
+	record := w.WorklogRecord
+	record.Comment = commentText(w.Comment)
+	return record
+}
+
+// worklogRecords converts REST worklogs to go-jira worklogs
+func worklogRecords(worklogs []worklogRecord) (records []jira.WorklogRecord) {
+	for _, worklog := range worklogs {
+		records = append(records, worklog.record())
+	}
+	return
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func TestCommentTextPlain(t *testing.T) {
+	comment := "{braces} are fine in plain comments"
+	raw, _ := json.Marshal(comment)
+
+	if text := commentText(raw); text != comment {
+		t.Errorf("Plain comment was changed, got: %q, want: %q.", text, comment)
+	}
+}
+
+func TestCommentTextEmpty(t *testing.T) {
+	if text := commentText(nil); text != "" {
+		t.Errorf("Missing comment should be empty, got: %q.", text)
+	}
+}
+
+func TestCommentTextADF(t *testing.T) {
+	comment := `{"type": "doc", "version": 1, "content": [
+		{"type": "paragraph", "content": [
+			{"type": "text", "text": "Reviewed with "},
+			{"type": "mention", "attrs": {"id": "123", "text": "@Anna"}},
+			{"type": "hardBreak"},
+			{"type": "text", "text": "second line"}
+		]},
+		{"type": "bulletList", "content": [
+			{"type": "listItem", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "item"}]}]}
+		]}
+	]}`
+
+	expected := "Reviewed with @Anna\nsecond line\n- item"
+	if text := commentText(json.RawMessage(comment)); text != expected {
+		t.Errorf("Wrong text, got: %q, want: %q.", text, expected)
+	}
+}
+
+func TestWorklogsV3(t *testing.T) {
+	payload, err := ioutil.ReadFile(filepath.Join("testdata", "worklog-v3.json"))
+	if err != nil {
+		t.Fatalf("Unable to read payload %s", err)
+	}
+
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	client, _ := jira.NewClient(nil, server.URL)
+	records, _, err := jiraWorklogs{client}.Worklogs(context.Background(), "AA-1234")
+	if err != nil {
+		t.Fatalf("Unable to decode v3 worklogs %s", err)
+	}
+
+	if path != "/rest/api/3/issue/AA-1234/worklog" {
+		t.Errorf("Wrong endpoint, got: %s.", path)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Wrong number of worklogs, got: %d, want: %d.", len(records), 2)
+	}
+
+	first := records[0]
+	if first.Comment != "Paired on the login bug\n- flaky test" {
+		t.Errorf("Wrong ADF comment, got: %q.", first.Comment)
+	}
+	if first.Author.AccountID != "5b10a2844c20165700ede21g" || first.TimeSpentSeconds != 12000 || first.ID != "100028" {
+		t.Errorf("Wrong worklog, got: %+v.", first)
+	}
+	if records[1].Comment != "" {
+		t.Errorf("Worklog without a comment should be empty, got: %q.", records[1].Comment)
+	}
+}
+
+func TestWorklogsFallBackToV2(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		// Jira Server and Data Center have no REST v3
+		if strings.HasPrefix(r.URL.Path, "/rest/api/3/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "POST" {
+			w.Write([]byte(`[{"id": "100029", "issueId": "10002", "comment": "Listed on v2", "timeSpentSeconds": 1800}]`))
+			return
+		}
+		w.Write([]byte(`{"worklogs": [{"id": "100028", "comment": "Plain text on v2", "timeSpentSeconds": 3600}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := jira.NewClient(nil, server.URL)
+	records, _, err := jiraWorklogs{client}.Worklogs(context.Background(), "AA-1234")
+	if err != nil {
+		t.Fatalf("Unable to fall back to v2 %s", err)
+	}
+	if len(records) != 1 || records[0].Comment != "Plain text on v2" || records[0].ID != "100028" {
+		t.Errorf("Wrong v2 worklogs, got: %+v.", records)
+	}
+
+	listed, err := jiraWorklogChanges{client: client}.List([]int{100029})
+	if err != nil || len(listed) != 1 || listed[0].Comment != "Listed on v2" {
+		t.Errorf("Wrong v2 worklog list, got: %+v, %v.", listed, err)
+	}
+
+	expected := "GET /rest/api/3/issue/AA-1234/worklog,GET /rest/api/2/issue/AA-1234/worklog," +
+		"POST /rest/api/3/worklog/list,POST /rest/api/2/worklog/list"
+	if strings.Join(paths, ",") != expected {
+		t.Errorf("Wrong requests, got: %s, want: %s.", strings.Join(paths, ","), expected)
+	}
+}
+
+func TestWorklogsMissingIssue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, _ := jira.NewClient(nil, server.URL)
+	_, resp, err := jiraWorklogs{client}.Worklogs(context.Background(), "AA-9999")
+	if err == nil || resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("A missing issue should still be not found, got: %v.", err)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// noBreakdownName is shown for time entries without a project, epic or field
+const noBreakdownName = "(none)"
+
+// A BreakdownRow is the time spent on one project, epic or field value
+type BreakdownRow struct {
+	Name    string
+	Hours   float32
+	Percent float32
+}
+
+// Breakdown sums the hours per name, most hours first,
+// together with their share of the total
+func Breakdown(timeEntries []TimeEntry, nameOf func(TimeEntry) string) (rows []BreakdownRow) {
+	var total float32 = 0.0
+	hours := make(map[string]float32)
+	for _, entry := range timeEntries {
+		name := nameOf(entry)
+		if name == "" {
+			name = noBreakdownName
+		}
+		hours[name] += entry.Hours
+		total += entry.Hours
+	}
+
+	for name, sum := range hours {
+		row := BreakdownRow{Name: name, Hours: sum}
+		if total > 0 {
+			row.Percent = 100 * sum / total
+		}
+		rows = append(rows, row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Hours == rows[j].Hours {
+			return rows[i].Name < rows[j].Name
+		}
+		return rows[i].Hours > rows[j].Hours
+	})
+	return
+}
+
+func writeBreakdown(out *bytes.Buffer, title string, rows []BreakdownRow) {
+	width := 0
+	for _, row := range rows {
+		if len(row.Name) > width {
+			width = len(row.Name)
+		}
+	}
+
+	out.WriteString(fmt.Sprintf("%s\n", title))
+	for _, row := range rows {
+		out.WriteString(fmt.Sprintf("\t%-*s %6.2f %5.1f%%\n", width+1, row.Name+":", row.Hours, row.Percent))
+	}
+	out.WriteString("\n")
+}
+
+// PrettyPrintBreakdown prints the hours per project, per epic and
+// per value of each configured custom field
+func PrettyPrintBreakdown(timeEntries []TimeEntry, config ChronosConfig) (out bytes.Buffer) {
+	if len(timeEntries) == 0 {
+		return
+	}
+
+	out.WriteString("===========================\n")
+	out.WriteString("Breakdown\n")
+	out.WriteString("===========================\n")
+	out.WriteString("\n")
+
+	writeBreakdown(&out, "Project", Breakdown(timeEntries, timeEntryProject))
+	writeBreakdown(&out, "Epic", Breakdown(timeEntries, func(entry TimeEntry) string {
+		return entry.Epic
+	}))
+
+	for _, name := range sortedKeys(config.Jira.CustomFields) {
+		writeBreakdown(&out, name, Breakdown(timeEntries, func(entry TimeEntry) string {
+			return entry.Fields[name]
+		}))
+	}
+
+	var total float32 = 0.0
+	for _, entry := range timeEntries {
+		total += entry.Hours
+	}
+	out.WriteString(fmt.Sprintf("\tTotal:   %6.2f\n", total))
+	return
+}
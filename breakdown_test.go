@@ -0,0 +1,71 @@
+package main
+
+import "testing"
+
+func breakdownEntries() []TimeEntry {
+	a := timeEntry1
+	a.Project = "AA"
+	a.Epic = "AA-100"
+	a.Fields = map[string]string{"team": "Platform"}
+
+	b := timeEntry2
+	b.Project = "AA"
+	b.Epic = "AA-100"
+
+	c := timeEntry3
+	c.Issue = "BB-1"
+
+	return []TimeEntry{a, b, c, timeEntry4}
+}
+
+func TestBreakdown(t *testing.T) {
+	rows := Breakdown(breakdownEntries(), func(entry TimeEntry) string {
+		return entry.Epic
+	})
+
+	if len(rows) != 2 {
+		t.Fatalf("Wrong number of rows, got: %d, want: %d.", len(rows), 2)
+	}
+
+	if rows[0].Name != noBreakdownName || rows[0].Hours != 7 || rows[0].Percent != 70 {
+		t.Errorf("Wrong first row, got: %+v.", rows[0])
+	}
+
+	if rows[1].Name != "AA-100" || rows[1].Hours != 3 || rows[1].Percent != 30 {
+		t.Errorf("Wrong second row, got: %+v.", rows[1])
+	}
+}
+
+func TestPrettyPrintBreakdown(t *testing.T) {
+	config := DefaultConfig()
+	config.CustomFields = map[string]string{"team": "customfield_10100"}
+	output := PrettyPrintBreakdown(breakdownEntries(), config)
+
+	expected := "===========================\n" +
+		"Breakdown\n" +
+		"===========================\n" +
+		"\n" +
+		"Project\n" +
+		"\tAA:   7.00  70.0%\n" +
+		"\tBB:   3.00  30.0%\n" +
+		"\n" +
+		"Epic\n" +
+		"\t(none):   7.00  70.0%\n" +
+		"\tAA-100:   3.00  30.0%\n" +
+		"\n" +
+		"team\n" +
+		"\t(none):     9.00  90.0%\n" +
+		"\tPlatform:   1.00  10.0%\n" +
+		"\n" +
+		"\tTotal:    10.00\n"
+	if output.String() != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output.String(), expected)
+	}
+}
+
+func TestPrettyPrintBreakdownEmpty(t *testing.T) {
+	output := PrettyPrintBreakdown([]TimeEntry{}, DefaultConfig())
+	if output.Len() != 0 {
+		t.Errorf("Nothing to break down, got:\n%s", output.String())
+	}
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// worklogListLimit is the most worklog IDs Jira accepts in one list request
+const worklogListLimit = 1000
+
+// cacheVersion changes whenever the cached time entries change shape,
+// e.g, when the week became an ISOWeek. Older caches are thrown away.
+const cacheVersion = 1
+
+// A WorklogCache is the local copy of the user's time entries,
+// keyed by worklog ID. Since is the Jira timestamp (in milliseconds)
+// we have seen all worklog updates up to.
+type WorklogCache struct {
+	Settings string               `json:"settings"`
+	From     string               `json:"from"`
+	Since    int64                `json:"since"`
+	Synced   time.Time            `json:"synced"`
+	Entries  map[string]TimeEntry `json:"entries"`
+	// DeletedSince follows the deleted worklogs on its own, since Jira
+	// only moves that cursor forward when something was deleted
+	DeletedSince int64 `json:"deletedSince"`
+	// Version is the cacheVersion the cache was written with
+	Version int `json:"version"`
+}
+
+// worklogChanges is the part of Jira we need for an incremental sync
+type worklogChanges interface {
+	Updated(since int64) (ids []int, until int64, err error)
+	Deleted(since int64) (ids []int, until int64, err error)
+	List(ids []int) ([]jira.WorklogRecord, error)
+	Issue(id string) (jira.Issue, error)
+}
+
+// chronosFile is the path to a file in the .chronos folder in home
+func chronosFile(name string) (string, error) {
+	usr, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(usr.HomeDir, ".chronos", name), nil
+}
+
+// readJSONFile decodes a JSON file. A missing file leaves v untouched.
+func readJSONFile(file string, v interface{}) error {
+	raw, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+// writeJSONFile encodes v to a JSON file. We write to a temporary file
+// first so an interrupted run never leaves a broken file behind.
+func writeJSONFile(file string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(file), 0700)
+	if err != nil {
+		return err
+	}
+
+	tmpFile := file + ".tmp"
+	err = ioutil.WriteFile(tmpFile, data, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, file)
+}
+
+// CacheFile is where the worklog cache is kept in the home folder
+func CacheFile() (string, error) {
+	return chronosFile("worklogs.json")
+}
+
+// LoadCache reads the worklog cache. A missing file is an empty cache.
+// A cache from another version of chronos is empty too, but with an
+// error to tell why.
+func LoadCache(cacheFile string) (cache WorklogCache, err error) {
+	err = readJSONFile(cacheFile, &cache)
+	if err != nil {
+		return WorklogCache{}, err
+	}
+	if !cache.Synced.IsZero() && cache.Version != cacheVersion {
+		return WorklogCache{}, fmt.Errorf("cache version %d, want %d", cache.Version, cacheVersion)
+	}
+	return cache, nil
+}
+
+// SaveCache writes the worklog cache
+func SaveCache(cacheFile string, cache WorklogCache) error {
+	return writeJSONFile(cacheFile, &cache)
+}
+
+// cacheSettings are the parts of the config that change which time
+// entries end up in the cache. If they change we need a full resync.
+func cacheSettings(config ChronosConfig) string {
+	return fmt.Sprintf("%s|%s|%s|%t|%s", config.Jira.Username, config.Jira.AccountID, config.Jira.TimeZone, config.Jira.UseCreated, strings.Join(issueFields(config), ","))
+}
+
+// covers tells if the cache can be brought up to date incrementally
+func (c WorklogCache) covers(config ChronosConfig, pastDate string) bool {
+	return c.Since > 0 && c.Settings == cacheSettings(config) && c.From <= pastDate
+}
+
+// TimeEntriesIn returns the cached time entries in a date range
+func (c WorklogCache) TimeEntriesIn(dates DateRange) (timeEntries []TimeEntry) {
+	for _, entry := range c.Entries {
+		if dates.Contains(entry.Date) {
+			timeEntries = append(timeEntries, entry)
+		}
+	}
+
+	sort.Slice(timeEntries, func(i, j int) bool {
+		if timeEntries[i].Started.Equal(timeEntries[j].Started) {
+			return timeEntries[i].WorklogID < timeEntries[j].WorklogID
+		}
+		return timeEntries[i].Started.Before(timeEntries[j].Started)
+	})
+	return
+}
+
+// knownIssues maps issue IDs to a time entry with the
+// details of the issues we already have in the cache
+func (c WorklogCache) knownIssues() map[string]TimeEntry {
+	issues := make(map[string]TimeEntry)
+	for _, entry := range c.Entries {
+		issues[entry.IssueID] = entry
+	}
+	return issues
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func fullSync(client *jira.Client, config ChronosConfig, cache *WorklogCache, pastDate string) error {
+	// Anything changed while we are fetching is picked up next time
+	since := millis(time.Now())
+
+	// The cache follows all new worklogs, so there is no upper bound
+	timeEntries, err := ExtractTimeEntriesFromJira(client, config, DateRange{From: pastDate})
+	if err != nil && errorKind(err) != PartialError {
+		return err
+	}
+
+	*cache = WorklogCache{
+		Settings:     cacheSettings(config),
+		From:         pastDate,
+		Since:        since,
+		Synced:       time.Now(),
+		Entries:      make(map[string]TimeEntry),
+		DeletedSince: since,
+		Version:      cacheVersion,
+	}
+
+	for _, entry := range timeEntries {
+		cache.Entries[entry.WorklogID] = entry
+	}
+
+	return err
+}
+
+func incrementalSync(changes worklogChanges, config ChronosConfig, cache *WorklogCache) error {
+	updated, until, err := changes.Updated(cache.Since)
+	if err != nil {
+		return err
+	}
+
+	// Caches from before the deleted cursor start where the updates are
+	deletedSince := cache.DeletedSince
+	if deletedSince == 0 {
+		deletedSince = cache.Since
+	}
+	deleted, deletedUntil, err := changes.Deleted(deletedSince)
+	if err != nil {
+		return err
+	}
+
+	records, err := changes.List(updated)
+	if err != nil {
+		return err
+	}
+
+	issues := cache.knownIssues()
+	unreachable := make(map[string]bool)
+	for _, record := range records {
+		stub := jira.Issue{ID: record.IssueID, Fields: &jira.IssueFields{}}
+		entry := issueAndWorklogToTimeEntry(stub, record, config)
+
+		if !usersTimeEntry(entry, config) || entry.Date < cache.From {
+			delete(cache.Entries, entry.WorklogID)
+			continue
+		}
+
+		if unreachable[entry.IssueID] {
+			delete(cache.Entries, entry.WorklogID)
+			continue
+		}
+
+		known, ok := issues[entry.IssueID]
+		if !ok {
+			issue, err := changes.Issue(entry.IssueID)
+			// An issue that was deleted or that we lost access to will
+			// never come back, so drop its worklogs instead of failing
+			if kind := errorKind(err); kind == NotFoundError || kind == AuthError {
+				log.Printf("[cache] Dropping worklogs on unreachable issue %s", err)
+				unreachable[entry.IssueID] = true
+				delete(cache.Entries, entry.WorklogID)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			setIssueDetails(&known, issue, config)
+			issues[entry.IssueID] = known
+		}
+
+		copyIssueDetails(&entry, known)
+		cache.Entries[entry.WorklogID] = entry
+	}
+
+	for _, id := range deleted {
+		delete(cache.Entries, strconv.Itoa(id))
+	}
+
+	log.Printf("[cache] %d updated and %d deleted worklogs", len(updated), len(deleted))
+
+	if until > cache.Since {
+		cache.Since = until
+	}
+	cache.DeletedSince = deletedSince
+	if deletedUntil > deletedSince {
+		cache.DeletedSince = deletedUntil
+	}
+	cache.Synced = time.Now()
+	return nil
+}
+
+// SyncTimeEntries returns the user's time entries from the local cache
+// after bringing it up to date with Jira. Only worklogs changed since the
+// last run are fetched, unless refresh is set or the cache is unusable.
+func SyncTimeEntries(client *jira.Client, config ChronosConfig, refresh bool) ([]TimeEntry, error) {
+	cacheFile, err := CacheFile()
+	if err != nil {
+		return []TimeEntry{}, err
+	}
+
+	cache, err := LoadCache(cacheFile)
+	if err != nil {
+		log.Printf("[cache] Ignoring unreadable cache %s", err)
+		cache = WorklogCache{}
+	}
+
+	dates := config.ReportRange()
+	today := time.Now().In(config.Location()).Format(dateLayout)
+	switch {
+	case len(config.Users) > 0:
+		// The cache only follows our own worklogs
+		log.Printf("[cache] Fetching worklogs of %s without the cache", strings.Join(config.Users, ", "))
+		return ExtractTimeEntriesFromJira(client, config, dates)
+	case !refresh && cache.covers(config, dates.From):
+		err = incrementalSync(jiraWorklogChanges{client, config}, config, &cache)
+	case dates.To < today:
+		// A range in the past says nothing about the weeks after
+		// it, so fetch it directly and leave the cache alone
+		log.Printf("[cache] Fetching %s to %s without the cache", dates.From, dates.To)
+		return ExtractTimeEntriesFromJira(client, config, dates)
+	default:
+		log.Printf("[cache] Full sync from %s", dates.From)
+		err = fullSync(client, config, &cache, dates.From)
+	}
+
+	if err != nil && errorKind(err) != PartialError {
+		return []TimeEntry{}, err
+	}
+
+	// A partial sync would leave holes in the cache, so only keep complete ones
+	if err == nil {
+		if saveErr := SaveCache(cacheFile, cache); saveErr != nil {
+			log.Printf("[cache] Unable to save cache %s", saveErr)
+		}
+	}
+
+	return cache.TimeEntriesIn(dates), err
+}
+
+// jiraWorklogChanges talks to the worklog change endpoints in Jira
+type jiraWorklogChanges struct {
+	client *jira.Client
+	config ChronosConfig
+}
+
+type worklogChangePage struct {
+	Values []struct {
+		WorklogID int `json:"worklogId"`
+	} `json:"values"`
+	Until    int64 `json:"until"`
+	LastPage bool  `json:"lastPage"`
+}
+
+func (c jiraWorklogChanges) changedSince(endpoint string, since int64) (ids []int, until int64, err error) {
+	until = since
+	for {
+		req, err := c.client.NewRequest("GET", fmt.Sprintf("%s?since=%d", endpoint, since), nil)
+		if err != nil {
+			return ids, until, err
+		}
+
+		var page worklogChangePage
+		resp, err := c.client.Do(req, &page)
+		if err != nil {
+			return ids, until, classifyError(endpoint, resp, err)
+		}
+
+		for _, value := range page.Values {
+			ids = append(ids, value.WorklogID)
+		}
+
+		if page.Until > until {
+			until = page.Until
+		}
+
+		if page.LastPage || page.Until <= since {
+			return ids, until, nil
+		}
+		since = page.Until
+	}
+}
+
+func (c jiraWorklogChanges) Updated(since int64) ([]int, int64, error) {
+	return c.changedSince("rest/api/2/worklog/updated", since)
+}
+
+func (c jiraWorklogChanges) Deleted(since int64) ([]int, int64, error) {
+	return c.changedSince("rest/api/2/worklog/deleted", since)
+}
+
+func (c jiraWorklogChanges) List(ids []int) (records []jira.WorklogRecord, err error) {
+	for start := 0; start < len(ids); start += worklogListLimit {
+		end := start + worklogListLimit
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		body := struct {
+			IDs []int `json:"ids"`
+		}{ids[start:end]}
+
+		var page []worklogRecord
+		resp, err := doWorklogRequest(context.Background(), c.client, "POST", "worklog/list", &body, &page)
+		if err != nil {
+			return records, classifyError("worklog list", resp, err)
+		}
+		records = append(records, worklogRecords(page)...)
+	}
+	return
+}
+
+func (c jiraWorklogChanges) Issue(id string) (jira.Issue, error) {
+	fields := strings.Join(issueFields(c.config), ",")
+	issue, resp, err := c.client.Issue.Get(id, &jira.GetQueryOptions{Fields: fields})
+	if err != nil {
+		return jira.Issue{}, classifyError(fmt.Sprintf("issue %s", id), resp, err)
+	}
+	if issue.Fields == nil {
+		issue.Fields = &jira.IssueFields{}
+	}
+	return *issue, nil
+}
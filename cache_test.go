@@ -0,0 +1,261 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// fakeWorklogChanges serves a fixed set of changes and counts issue lookups
+type fakeWorklogChanges struct {
+	records      []jira.WorklogRecord
+	deleted      []int
+	until        int64
+	deletedUntil int64
+	issues       map[string]jira.Issue
+	issueErrors  map[string]error
+	lookups      int
+}
+
+func (f *fakeWorklogChanges) Updated(since int64) (ids []int, until int64, err error) {
+	for range f.records {
+		ids = append(ids, 0)
+	}
+	return ids, f.until, nil
+}
+
+func (f *fakeWorklogChanges) Deleted(since int64) ([]int, int64, error) {
+	if f.deletedUntil != 0 {
+		return f.deleted, f.deletedUntil, nil
+	}
+	return f.deleted, f.until, nil
+}
+
+func (f *fakeWorklogChanges) List(ids []int) ([]jira.WorklogRecord, error) {
+	return f.records, nil
+}
+
+func (f *fakeWorklogChanges) Issue(id string) (jira.Issue, error) {
+	f.lookups++
+	return f.issues[id], f.issueErrors[id]
+}
+
+func cacheRecord(id, issueID, author, started string) jira.WorklogRecord {
+	startedTime, _ := time.Parse(time.RFC3339, started)
+	stamp := jira.Time(startedTime)
+
+	return jira.WorklogRecord{
+		ID:               id,
+		IssueID:          issueID,
+		Author:           &jira.User{Name: author},
+		Started:          &stamp,
+		Created:          &stamp,
+		TimeSpentSeconds: 7200,
+	}
+}
+
+func cacheConfig() ChronosConfig {
+	config := DefaultConfig()
+	config.Username = "maxx"
+	config.TimeZone = "UTC"
+	return config
+}
+
+func cachedEntries() map[string]TimeEntry {
+	return map[string]TimeEntry{
+		"1": {WorklogID: "1", IssueID: "100", Issue: issueA, Summary: summaryA, Employee: "maxx", Date: "2020-01-06", Hours: 1},
+		"2": {WorklogID: "2", IssueID: "100", Issue: issueA, Summary: summaryA, Employee: "maxx", Date: "2020-01-07", Hours: 1},
+	}
+}
+
+func TestIncrementalSync(t *testing.T) {
+	config := cacheConfig()
+	cache := WorklogCache{Settings: cacheSettings(config), From: "2020-01-01", Since: 10, Entries: cachedEntries()}
+
+	changes := &fakeWorklogChanges{
+		records: []jira.WorklogRecord{
+			cacheRecord("1", "100", "maxx", "2020-01-06T08:00:00Z"),
+			cacheRecord("3", "200", "maxx", "2020-01-08T08:00:00Z"),
+			cacheRecord("4", "200", "someoneelse", "2020-01-08T08:00:00Z"),
+		},
+		deleted: []int{2},
+		until:   20,
+		issues: map[string]jira.Issue{
+			"200": {ID: "200", Key: issueB, Fields: &jira.IssueFields{Summary: summaryB}},
+		},
+	}
+
+	err := incrementalSync(changes, config, &cache)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+
+	if len(cache.Entries) != 2 {
+		t.Fatalf("Wrong number of cached entries, got: %d, want: %d.", len(cache.Entries), 2)
+	}
+
+	if cache.Entries["1"].Hours != 2 || cache.Entries["1"].Issue != issueA {
+		t.Errorf("Updated worklog was not merged, got: %+v", cache.Entries["1"])
+	}
+
+	if cache.Entries["3"].Issue != issueB || cache.Entries["3"].Summary != summaryB {
+		t.Errorf("New worklog has wrong issue, got: %+v", cache.Entries["3"])
+	}
+
+	if _, ok := cache.Entries["2"]; ok {
+		t.Errorf("Deleted worklog is still cached")
+	}
+
+	if changes.lookups != 1 {
+		t.Errorf("Wrong number of issue lookups, got: %d, want: %d.", changes.lookups, 1)
+	}
+
+	if cache.Since != 20 {
+		t.Errorf("Since was not moved forward, got: %d, want: %d.", cache.Since, 20)
+	}
+}
+
+func TestIncrementalSyncWithoutDeletions(t *testing.T) {
+	config := cacheConfig()
+	cache := WorklogCache{Settings: cacheSettings(config), From: "2020-01-01", Since: 10, Entries: cachedEntries()}
+
+	// Jira leaves the deleted cursor where it was when nothing was deleted
+	changes := &fakeWorklogChanges{until: 20, deletedUntil: 10}
+
+	err := incrementalSync(changes, config, &cache)
+	if err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+
+	if cache.Since != 20 {
+		t.Errorf("Since should move without deletions, got: %d, want: %d.", cache.Since, 20)
+	}
+	if cache.DeletedSince != 10 {
+		t.Errorf("Wrong deleted cursor, got: %d, want: %d.", cache.DeletedSince, 10)
+	}
+}
+
+func TestIncrementalSyncDropsUnreachableIssues(t *testing.T) {
+	config := cacheConfig()
+	cache := WorklogCache{Settings: cacheSettings(config), From: "2020-01-01", Since: 10, Entries: cachedEntries()}
+
+	notFound := &JiraError{Kind: NotFoundError, Op: "issue 200", Err: errors.New("404")}
+	forbidden := &JiraError{Kind: AuthError, Op: "issue 300", Err: errors.New("403")}
+	changes := &fakeWorklogChanges{
+		records: []jira.WorklogRecord{
+			cacheRecord("3", "200", "maxx", "2020-01-08T08:00:00Z"),
+			cacheRecord("4", "200", "maxx", "2020-01-09T08:00:00Z"),
+			cacheRecord("5", "300", "maxx", "2020-01-09T08:00:00Z"),
+		},
+		until:       20,
+		issueErrors: map[string]error{"200": notFound, "300": forbidden},
+	}
+
+	err := incrementalSync(changes, config, &cache)
+	if err != nil {
+		t.Fatalf("Unreachable issues should not fail the sync, got: %s", err)
+	}
+
+	if len(cache.Entries) != 2 {
+		t.Errorf("Worklogs on unreachable issues should be dropped, got: %d entries.", len(cache.Entries))
+	}
+	if changes.lookups != 2 {
+		t.Errorf("Each unreachable issue should be looked up once, got: %d.", changes.lookups)
+	}
+	if cache.Since != 20 {
+		t.Errorf("Since was not moved forward, got: %d, want: %d.", cache.Since, 20)
+	}
+}
+
+func TestIncrementalSyncFailsOnOtherErrors(t *testing.T) {
+	config := cacheConfig()
+	cache := WorklogCache{Settings: cacheSettings(config), From: "2020-01-01", Since: 10, Entries: cachedEntries()}
+
+	changes := &fakeWorklogChanges{
+		records:     []jira.WorklogRecord{cacheRecord("3", "200", "maxx", "2020-01-08T08:00:00Z")},
+		until:       20,
+		issueErrors: map[string]error{"200": errors.New("connection reset")},
+	}
+
+	if err := incrementalSync(changes, config, &cache); err == nil {
+		t.Errorf("Other errors should fail the sync")
+	}
+}
+
+func TestCacheCovers(t *testing.T) {
+	config := cacheConfig()
+	cache := WorklogCache{Settings: cacheSettings(config), From: "2020-01-01", Since: 10}
+
+	if !cache.covers(config, "2020-01-06") {
+		t.Errorf("Cache should cover a later date")
+	}
+
+	if cache.covers(config, "2019-12-30") {
+		t.Errorf("Cache should not cover an earlier date")
+	}
+
+	config.Username = "someoneelse"
+	if cache.covers(config, "2020-01-06") {
+		t.Errorf("Cache should not cover another user")
+	}
+
+	if (WorklogCache{}).covers(cacheConfig(), "2020-01-06") {
+		t.Errorf("Empty cache should not cover anything")
+	}
+}
+
+func TestSaveAndLoadCache(t *testing.T) {
+	cacheFile := filepath.Join(os.TempDir(), "chronos-cache", "worklogs.json")
+	defer os.RemoveAll(filepath.Dir(cacheFile))
+
+	cache := WorklogCache{From: "2020-01-01", Since: 10, Entries: cachedEntries()}
+	err := SaveCache(cacheFile, cache)
+	if err != nil {
+		t.Fatalf("Unable to save cache %s", err)
+	}
+
+	loaded, err := LoadCache(cacheFile)
+	if err != nil {
+		t.Fatalf("Unable to load cache %s", err)
+	}
+
+	timeEntries := loaded.TimeEntriesIn(DateRange{From: "2020-01-07"})
+	if len(timeEntries) != 1 || timeEntries[0].WorklogID != "2" {
+		t.Errorf("Wrong time entries from cache, got: %+v", timeEntries)
+	}
+}
+
+func TestLoadOldCache(t *testing.T) {
+	cacheFile := filepath.Join(os.TempDir(), "chronos-old-cache", "worklogs.json")
+	defer os.RemoveAll(filepath.Dir(cacheFile))
+
+	olds := []string{
+		// Before the week was an ISOWeek
+		`{"since": 10, "synced": "2020-01-08T10:00:00Z", "entries": {"1": {"Week": 2, "Date": "2020-01-06"}}}`,
+		// Before the cache had a version
+		`{"since": 10, "synced": "2020-01-08T10:00:00Z", "entries": {"1": {"Week": {"Year": 2020, "Week": 2}, "Date": "2020-01-06"}}}`,
+	}
+
+	for _, old := range olds {
+		os.MkdirAll(filepath.Dir(cacheFile), 0700)
+		ioutil.WriteFile(cacheFile, []byte(old), 0600)
+
+		cache, err := LoadCache(cacheFile)
+		if err == nil || len(cache.Entries) != 0 || !cache.Synced.IsZero() {
+			t.Errorf("Old cache should be empty, got: %+v, %v", cache, err)
+		}
+	}
+}
+
+func TestLoadMissingCache(t *testing.T) {
+	cache, err := LoadCache(filepath.Join(os.TempDir(), "chronos-does-not-exist.json"))
+
+	if err != nil || len(cache.Entries) != 0 {
+		t.Errorf("Missing cache should be empty, got: %+v, %v", cache, err)
+	}
+}
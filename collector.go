@@ -1,41 +1,189 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/andygrunwald/go-jira"
 )
 
+// An ISOWeek is a week number together with its ISO week-numbering
+// year. Around New Year it is not always the year of the date, e.g,
+// 2026-12-31 is in 2026-W53 and 2027-01-01 is in 2026-W53 too.
+type ISOWeek struct {
+	Year int
+	Week int
+}
+
+// ISOWeekOf returns the ISO week a time is in
+func ISOWeekOf(t time.Time) ISOWeek {
+	year, week := t.ISOWeek()
+	return ISOWeek{Year: year, Week: week}
+}
+
+// String formats the week like 2026-W42
+func (w ISOWeek) String() string {
+	return fmt.Sprintf("%04d-W%02d", w.Year, w.Week)
+}
+
 // A TimeEntry represent a worklog that was entered in JIRA
 type TimeEntry struct {
 	Issue        string
 	Summary      string
 	Employee     string
 	EmailAddress string
-	Date         string
-	Hours        float32
-	Comment      string
-	Week         int
+	// AuthorAccountID identifies the author on Jira Cloud,
+	// where names and mails can be hidden
+	AuthorAccountID string
+	Date            string
+	Hours           float32
+	Comment         string
+	Week            ISOWeek
+	Started         time.Time
+	WorklogID       string
+	IssueID         string
+	Updated         time.Time
+	Project         string
+	Epic            string
+	Fields          map[string]string
 }
 
 type timeEntryPredicate func(TimeEntry) bool
 
-func issueAndWorklogToTimeEntry(issue jira.Issue, worklog jira.WorklogRecord) (entry TimeEntry) {
+// worklogTime is the moment a worklog is bucketed by, in the configured
+// time zone. Work logged on Monday for last Friday belongs to Friday,
+// so we use Started unless the user asked for Created.
+func worklogTime(worklog jira.WorklogRecord, config ChronosConfig) time.Time {
+	stamp := worklog.Started
+	if config.Jira.UseCreated || stamp == nil {
+		stamp = worklog.Created
+	}
+	return time.Time(*stamp).In(config.Location())
+}
+
+// issueFields are the issue fields the collector asks Jira for
+func issueFields(config ChronosConfig) []string {
+	fields := []string{"key", "summary", "worklog", "project", "parent", "issuetype"}
+	if config.Jira.EpicField != "" {
+		fields = append(fields, config.Jira.EpicField)
+	}
+	for _, name := range sortedKeys(config.Jira.CustomFields) {
+		fields = append(fields, config.Jira.CustomFields[name])
+	}
+	return fields
+}
+
+func sortedKeys(m map[string]string) (keys []string) {
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return
+}
+
+// customFieldText is the text of a custom field value. Jira hands
+// them out as text, numbers, options, users or lists of those.
+func customFieldText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]interface{}:
+		for _, key := range []string{"value", "name", "key", "displayName"} {
+			if text, ok := v[key].(string); ok {
+				return text
+			}
+		}
+	case []interface{}:
+		var texts []string
+		for _, item := range v {
+			if text := customFieldText(item); text != "" {
+				texts = append(texts, text)
+			}
+		}
+		return strings.Join(texts, ", ")
+	}
+	return ""
+}
+
+// setIssueDetails copies what the report needs to know about an issue
+// into a time entry. The epic is the configured epic link field, or
+// else the parent, which is where team-managed projects keep the epic.
+// The parent of a sub-task is a story or a task, not an epic, so a
+// sub-task only has an epic through the epic link field.
+func setIssueDetails(entry *TimeEntry, issue jira.Issue, config ChronosConfig) {
 	entry.Issue = issue.Key
 	entry.Summary = issue.Fields.Summary
+
+	entry.Project = issue.Fields.Project.Key
+	if entry.Project == "" {
+		entry.Project = projectKey(issue.Key)
+	}
+
+	entry.Epic = ""
+	if config.Jira.EpicField != "" {
+		entry.Epic = customFieldText(issue.Fields.Unknowns[config.Jira.EpicField])
+	}
+	if entry.Epic == "" && issue.Fields.Parent != nil && !issue.Fields.Type.Subtask {
+		entry.Epic = issue.Fields.Parent.Key
+	}
+
+	entry.Fields = nil
+	for name, id := range config.Jira.CustomFields {
+		if value := customFieldText(issue.Fields.Unknowns[id]); value != "" {
+			if entry.Fields == nil {
+				entry.Fields = make(map[string]string)
+			}
+			entry.Fields[name] = value
+		}
+	}
+}
+
+// copyIssueDetails copies the issue details of one time entry to another
+func copyIssueDetails(entry *TimeEntry, from TimeEntry) {
+	entry.Issue = from.Issue
+	entry.Summary = from.Summary
+	entry.Project = from.Project
+	entry.Epic = from.Epic
+	entry.Fields = from.Fields
+}
+
+func issueAndWorklogToTimeEntry(issue jira.Issue, worklog jira.WorklogRecord, config ChronosConfig) (entry TimeEntry) {
+	started := worklogTime(worklog, config)
+
+	setIssueDetails(&entry, issue, config)
 	entry.Employee = worklog.Author.Name
 	entry.EmailAddress = worklog.Author.EmailAddress
-	entry.Date = time.Time(*worklog.Created).Format("2006-01-02")
+	entry.AuthorAccountID = worklog.Author.AccountID
+	entry.Date = started.Format("2006-01-02")
 	entry.Hours = float32(worklog.TimeSpentSeconds) / 3600
 	entry.Comment = worklog.Comment
+	entry.Started = started
+	entry.WorklogID = worklog.ID
+	entry.IssueID = issue.ID
+	if entry.IssueID == "" {
+		entry.IssueID = worklog.IssueID
+	}
+	if worklog.Updated != nil {
+		entry.Updated = time.Time(*worklog.Updated)
+	}
 
-	_, entry.Week = time.Time(*worklog.Created).ISOWeek()
+	entry.Week = ISOWeekOf(started)
 	return
 }
 
+func usersTimeEntry(entry TimeEntry, config ChronosConfig) bool {
+	_, ok := timeEntryUser(entry, config)
+	return ok
+}
+
 func filterTimeEntries(timeEntries []TimeEntry, predicate timeEntryPredicate) (ret []TimeEntry) {
 	for _, record := range timeEntries {
 		if predicate(record) {
@@ -45,35 +193,53 @@ func filterTimeEntries(timeEntries []TimeEntry, predicate timeEntryPredicate) (r
 	return
 }
 
-func extractAllWorklogsForIssues(client *jira.Client, issues []jira.Issue) (timeEntries []TimeEntry) {
-	for _, issue := range issues {
-		key := issue.Key
-		worklog, _, err := client.Issue.GetWorklogs(key)
-		if err != nil {
-			log.Fatalf("[collector] Unable to extract worklog for issue %s", err)
-		}
-		for _, worklogRecord := range worklog.Worklogs {
-			timeEntries = append(timeEntries, issueAndWorklogToTimeEntry(issue, worklogRecord))
+// jqlValue quotes values that are not plain words, like mail addresses
+func jqlValue(value string) string {
+	for _, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' && r != '-' {
+			return strconv.Quote(value)
 		}
 	}
-	return
+	return value
 }
 
-// ExtractTimeEntriesFromJira extracts the latest worklogs for a user
-func ExtractTimeEntriesFromJira(client *jira.Client, config ChronosConfig) ([]TimeEntry, error) {
-	searchOpts := &jira.SearchOptions{
-		StartAt:    0,
-		MaxResults: 1000,
-		Expand:     "worklog",
-		Fields:     []string{"key", "summary", "worklog"},
+// worklogJQL finds the issues the users logged time on in the date range
+func worklogJQL(config ChronosConfig, dates DateRange) string {
+	jql := fmt.Sprintf("worklogDate >= %s", dates.From)
+	if dates.To != "" {
+		jql += fmt.Sprintf(" && worklogDate <= %s", dates.To)
+	}
+
+	// Jira knows who we are, which works even when names are hidden
+	if len(config.Users) == 0 {
+		return jql + " && worklogAuthor = currentUser()"
 	}
 
-	pastDate := CalcPassedDate(config).Format("2006-01-02")
-	log.Printf("[collector] Query from %s for user %s", pastDate, config.Jira.Username)
-	searchString := fmt.Sprintf("worklogDate >= %s && worklogAuthor = %s", pastDate, config.Jira.Username)
-	issues, _, err := client.Issue.Search(searchString, searchOpts)
+	users := config.ReportUsers()
+	if len(users) == 1 {
+		return jql + fmt.Sprintf(" && worklogAuthor = %s", jqlValue(users[0]))
+	}
+
+	var values []string
+	for _, user := range users {
+		values = append(values, jqlValue(user))
+	}
+	return jql + fmt.Sprintf(" && worklogAuthor in (%s)", strings.Join(values, ", "))
+}
+
+// ExtractTimeEntriesFromJira extracts the worklogs for a user in a date range.
+// If some worklogs could not be fetched, the rest are returned
+// together with a PartialResultError.
+func ExtractTimeEntriesFromJira(client *jira.Client, config ChronosConfig, dates DateRange) ([]TimeEntry, error) {
+	searchOpts := jira.SearchOptions{
+		Expand: "worklog",
+		Fields: issueFields(config),
+	}
+
+	log.Printf("[collector] Query from %s for %s", dates.From, strings.Join(config.ReportUsers(), ", "))
+	searchString := worklogJQL(config, dates)
+	issues, err := SearchAllIssues(client.Issue, searchString, searchOpts, logSearchProgress("collector"))
 	if err != nil {
-		log.Fatalf("[collector] Search failed %s", err)
 		return []TimeEntry{}, err
 	}
 
@@ -81,22 +247,25 @@ func ExtractTimeEntriesFromJira(client *jira.Client, config ChronosConfig) ([]Ti
 
 	// To get all worklogs (more than 20), we need to iterate
 	// over each issue and do a new request
-	timeEntries := extractAllWorklogsForIssues(client, issues)
+	timeEntries, err := extractAllWorklogsForIssues(context.Background(), jiraWorklogs{client}, issues, config)
+	if err != nil && errorKind(err) != PartialError {
+		return []TimeEntry{}, err
+	}
 
 	employeeTimeEntries := filterTimeEntries(timeEntries, func(worklog TimeEntry) bool {
-		return worklog.Employee == config.Jira.Username || strings.HasPrefix(worklog.EmailAddress, config.Jira.Username)
+		return usersTimeEntry(worklog, config)
 	})
 
 	recentTimeEntries := filterTimeEntries(employeeTimeEntries, func(worklog TimeEntry) bool {
-		return worklog.Date >= pastDate
+		return dates.Contains(worklog.Date)
 	})
 
-	return recentTimeEntries, nil
+	return recentTimeEntries, err
 }
 
 // CalcPassedDate calculates the date in the passed
 func CalcPassedDate(config ChronosConfig) time.Time {
-	return CalcPassedDateFrom(time.Now(), config)
+	return CalcPassedDateFrom(time.Now().In(config.Location()), config)
 }
 
 // CalcPassedDateFrom calucates the date in the passed from given date
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/andygrunwald/go-jira"
 )
 
 func TestCalcPassedDate(t *testing.T) {
@@ -18,3 +21,202 @@ func TestCalcPassedDate(t *testing.T) {
 		t.Errorf("CalcPassedDateFrom is wrong, got: %s, want: %s.", date, correctDate)
 	}
 }
+
+func TestWorklogJQL(t *testing.T) {
+	config := DefaultConfig()
+
+	jql := worklogJQL(config, DateRange{From: "2026-09-01"})
+	if jql != "worklogDate >= 2026-09-01 && worklogAuthor = currentUser()" {
+		t.Errorf("Wrong JQL without upper bound, got: %s.", jql)
+	}
+
+	jql = worklogJQL(config, DateRange{From: "2026-09-01", To: "2026-09-30"})
+	if jql != "worklogDate >= 2026-09-01 && worklogDate <= 2026-09-30 && worklogAuthor = currentUser()" {
+		t.Errorf("Wrong JQL with upper bound, got: %s.", jql)
+	}
+}
+
+func worklogStartedAndCreated(started, created string) jira.WorklogRecord {
+	startedTime, _ := time.Parse(time.RFC3339, started)
+	createdTime, _ := time.Parse(time.RFC3339, created)
+	startedStamp := jira.Time(startedTime)
+	createdStamp := jira.Time(createdTime)
+
+	return jira.WorklogRecord{
+		Author:           &jira.User{Name: "maxx"},
+		Started:          &startedStamp,
+		Created:          &createdStamp,
+		TimeSpentSeconds: 3600,
+	}
+}
+
+func TestTimeEntryUsesStarted(t *testing.T) {
+	config := DefaultConfig()
+	config.TimeZone = "UTC"
+	issue := jira.Issue{Key: "AA-1234", Fields: &jira.IssueFields{}}
+
+	// Logged on Monday (week 3) for the Friday before (week 2)
+	worklog := worklogStartedAndCreated("2020-01-10T15:00:00Z", "2020-01-13T09:00:00Z")
+	entry := issueAndWorklogToTimeEntry(issue, worklog, config)
+
+	if entry.Date != "2020-01-10" || entry.Week.Week != 2 {
+		t.Errorf("Wrong bucket, got: %s week %d, want: %s week %d.", entry.Date, entry.Week.Week, "2020-01-10", 2)
+	}
+
+	config.UseCreated = true
+	entry = issueAndWorklogToTimeEntry(issue, worklog, config)
+
+	if entry.Date != "2020-01-13" || entry.Week.Week != 3 {
+		t.Errorf("Wrong bucket, got: %s week %d, want: %s week %d.", entry.Date, entry.Week.Week, "2020-01-13", 3)
+	}
+}
+
+func TestTimeEntryCrossMidnight(t *testing.T) {
+	config := DefaultConfig()
+	config.TimeZone = "Europe/Stockholm"
+	issue := jira.Issue{Key: "AA-1234", Fields: &jira.IssueFields{}}
+
+	// 23:30 UTC on Tuesday is already Wednesday in Stockholm
+	worklog := worklogStartedAndCreated("2020-01-07T23:30:00Z", "2020-01-07T23:30:00Z")
+	entry := issueAndWorklogToTimeEntry(issue, worklog, config)
+
+	if entry.Date != "2020-01-08" || entry.Week.Week != 2 {
+		t.Errorf("Wrong bucket, got: %s week %d, want: %s week %d.", entry.Date, entry.Week.Week, "2020-01-08", 2)
+	}
+}
+
+func TestTimeEntryCrossWeek(t *testing.T) {
+	config := DefaultConfig()
+	config.TimeZone = "Europe/Stockholm"
+	issue := jira.Issue{Key: "AA-1234", Fields: &jira.IssueFields{}}
+
+	// 23:30 UTC on Sunday of week 1 is Monday of week 2 in Stockholm
+	worklog := worklogStartedAndCreated("2020-01-05T23:30:00Z", "2020-01-05T23:30:00Z")
+	entry := issueAndWorklogToTimeEntry(issue, worklog, config)
+
+	if entry.Date != "2020-01-06" || entry.Week.Week != 2 {
+		t.Errorf("Wrong bucket, got: %s week %d, want: %s week %d.", entry.Date, entry.Week.Week, "2020-01-06", 2)
+	}
+
+	config.TimeZone = "UTC"
+	entry = issueAndWorklogToTimeEntry(issue, worklog, config)
+
+	if entry.Date != "2020-01-05" || entry.Week.Week != 1 {
+		t.Errorf("Wrong bucket, got: %s week %d, want: %s week %d.", entry.Date, entry.Week.Week, "2020-01-05", 1)
+	}
+}
+
+func TestISOWeekOf(t *testing.T) {
+	cases := []struct {
+		date string
+		week string
+	}{
+		{"2020-12-31", "2020-W53"},
+		{"2021-01-03", "2020-W53"},
+		{"2021-01-04", "2021-W01"},
+		{"2024-12-30", "2025-W01"},
+		{"2026-10-18", "2026-W42"},
+		{"2026-12-31", "2026-W53"},
+		{"2027-01-01", "2026-W53"},
+		{"2027-01-04", "2027-W01"},
+	}
+
+	for _, c := range cases {
+		date, _ := time.Parse("2006-01-02", c.date)
+		if week := ISOWeekOf(date).String(); week != c.week {
+			t.Errorf("Wrong week for %s, got: %s, want: %s.", c.date, week, c.week)
+		}
+	}
+}
+
+func TestTimeEntryWeekYear(t *testing.T) {
+	config := DefaultConfig()
+	config.TimeZone = "UTC"
+	issue := jira.Issue{Key: "AA-1234", Fields: &jira.IssueFields{}}
+
+	// Monday 2024-12-30 is in the first week of 2025
+	worklog := worklogStartedAndCreated("2024-12-30T09:00:00Z", "2024-12-30T09:00:00Z")
+	entry := issueAndWorklogToTimeEntry(issue, worklog, config)
+
+	if entry.Week != (ISOWeek{Year: 2025, Week: 1}) {
+		t.Errorf("Wrong week, got: %s, want: %s.", entry.Week, "2025-W01")
+	}
+}
+
+func TestIssueFields(t *testing.T) {
+	config := DefaultConfig()
+	config.EpicField = "customfield_10014"
+	config.CustomFields = map[string]string{"team": "customfield_10100", "client": "customfield_10200"}
+
+	fields := strings.Join(issueFields(config), ",")
+	expected := "key,summary,worklog,project,parent,issuetype,customfield_10014,customfield_10200,customfield_10100"
+	if fields != expected {
+		t.Errorf("Wrong fields, got: %s, want: %s.", fields, expected)
+	}
+}
+
+func TestCustomFieldText(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{"AA-100", "AA-100"},
+		{float64(3), "3"},
+		{map[string]interface{}{"value": "Platform"}, "Platform"},
+		{map[string]interface{}{"displayName": "Max"}, "Max"},
+		{[]interface{}{map[string]interface{}{"name": "a"}, "b"}, "a, b"},
+		{nil, ""},
+	}
+
+	for _, c := range cases {
+		if text := customFieldText(c.value); text != c.expected {
+			t.Errorf("Wrong text for %v, got: %s, want: %s.", c.value, text, c.expected)
+		}
+	}
+}
+
+func TestSetIssueDetails(t *testing.T) {
+	config := DefaultConfig()
+	config.EpicField = "customfield_10014"
+	config.CustomFields = map[string]string{"team": "customfield_10100"}
+
+	issue := jira.Issue{
+		Key: "AA-1234",
+		Fields: &jira.IssueFields{
+			Summary: "Summary",
+			Project: jira.Project{Key: "AA"},
+			Parent:  &jira.Parent{Key: "AA-1"},
+			Unknowns: map[string]interface{}{
+				"customfield_10014": "AA-100",
+				"customfield_10100": map[string]interface{}{"value": "Platform"},
+			},
+		},
+	}
+
+	var entry TimeEntry
+	setIssueDetails(&entry, issue, config)
+	if entry.Project != "AA" || entry.Epic != "AA-100" || entry.Fields["team"] != "Platform" {
+		t.Errorf("Wrong details, got: %s %s %v.", entry.Project, entry.Epic, entry.Fields)
+	}
+
+	// Without an epic link the parent is the epic
+	delete(issue.Fields.Unknowns, "customfield_10014")
+	setIssueDetails(&entry, issue, config)
+	if entry.Epic != "AA-1" {
+		t.Errorf("Wrong epic, got: %s, want: %s.", entry.Epic, "AA-1")
+	}
+
+	// The parent of a sub-task is a story, not an epic
+	issue.Fields.Type = jira.IssueType{Name: "Sub-task", Subtask: true}
+	setIssueDetails(&entry, issue, config)
+	if entry.Epic != "" {
+		t.Errorf("Wrong epic for a sub-task, got: %s, want none.", entry.Epic)
+	}
+
+	// Without a project the key tells which one it is
+	issue.Fields.Project = jira.Project{}
+	setIssueDetails(&entry, issue, config)
+	if entry.Project != "AA" {
+		t.Errorf("Wrong project, got: %s, want: %s.", entry.Project, "AA")
+	}
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os/user"
 	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
@@ -22,6 +24,14 @@ var (
 	DefaultWeeksLookback = 3
 	// DefaultHoursPerWeek is the normal work week
 	DefaultHoursPerWeek = 37.0
+	// DefaultHoursPerDay is the work day when nothing else is known
+	DefaultHoursPerDay = 8.0
+	// DefaultConcurrency is the number of parallel worklog requests
+	DefaultConcurrency = 4
+	// DefaultCSVColumns are the columns of the CSV export
+	DefaultCSVColumns = []string{"date", "week", "issue", "summary", "hours", "comment", "author", "project"}
+	// DefaultGroupBy is the grouping of the report
+	DefaultGroupBy = "week"
 )
 
 // Jira represent all configuration for Jira
@@ -30,14 +40,43 @@ type Jira struct {
 	Mail          string  `yaml:"mail"`
 	APIKey        string  `yaml:"apikey"`
 	Username      string  `yaml:"username"`
+	AccountID     string  `yaml:"accountid"`
 	WeeksLookback int     `yaml:"weekslookback"`
 	HoursPerWeek  float64 `yaml:"hoursperweek"`
+	HoursPerDay   float64 `yaml:"hoursperday"`
+	Concurrency   int     `yaml:"concurrency"`
+	TimeZone      string  `yaml:"timezone"`
+	UseCreated    bool    `yaml:"usecreated"`
+	EpicField     string  `yaml:"epicfield"`
+	// CustomFields maps names to custom field IDs, e.g, team: customfield_10100
+	CustomFields map[string]string `yaml:"customfields"`
+	// Teams are named lists of users for team reports
+	Teams map[string][]string `yaml:"teams"`
+	// TimerRounding rounds timers to this many minutes before they are logged
+	TimerRounding int `yaml:"timerrounding"`
+}
+
+// Report represent all configuration for how the report is printed
+type Report struct {
+	CSVColumns   []string `yaml:"csvcolumns"`
+	CSVAggregate bool     `yaml:"csvaggregate"`
+	NoLegend     bool     `yaml:"nolegend"`
+	ShortLines   bool     `yaml:"shortlines"`
+	Comments     bool     `yaml:"comments"`
+	GroupBy      string   `yaml:"groupby"`
+	Breakdown    bool     `yaml:"breakdown"`
 }
 
 // A ChronosConfig represents all the information we need to
 // connect to the JIRA Instance
 type ChronosConfig struct {
 	Jira
+	Report
+	Schedule
+
+	// Range and Users are picked on the command line, they are never saved
+	Range DateRange `yaml:"-"`
+	Users []string  `yaml:"-"`
 }
 
 // ReadConfig reads a YAML configuration from the home folder
@@ -68,6 +107,30 @@ func ReadConfigFile(configFile string) (ChronosConfig, error) {
 		config.WeeksLookback = DefaultWeeksLookback
 	}
 
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+
+	if len(config.CSVColumns) == 0 {
+		config.CSVColumns = DefaultCSVColumns
+	}
+
+	if config.GroupBy == "" {
+		config.GroupBy = DefaultGroupBy
+	}
+
+	if config.TimeZone != "" {
+		if _, err := time.LoadLocation(config.TimeZone); err != nil {
+			return config, err
+		}
+	}
+
+	for _, day := range config.DaysOff {
+		if _, err := time.Parse(dateLayout, day); err != nil {
+			return config, fmt.Errorf("day off %s is not a date like 2006-01-02", day)
+		}
+	}
+
 	return config, nil
 }
 
@@ -77,9 +140,37 @@ func CommandlineConfig(url, mail, username, apikey string) (c ChronosConfig) {
 	c.Jira.Mail = mail
 	c.Jira.Username = username
 	c.Jira.APIKey = apikey
+	c.Report.CSVColumns = DefaultCSVColumns
+	c.Report.GroupBy = DefaultGroupBy
 	return
 }
 
+// Location returns the time zone worklogs are bucketed in.
+// Without a configured time zone we use the local one.
+func (c ChronosConfig) Location() *time.Location {
+	if c.Jira.TimeZone == "" {
+		return time.Local
+	}
+
+	location, err := time.LoadLocation(c.Jira.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return location
+}
+
+// DayLength is the hours in a work day, used for durations like 1d.
+// Without hours per day, it is a fifth of the hours per week.
+func (c ChronosConfig) DayLength() float64 {
+	if c.Jira.HoursPerDay > 0 {
+		return c.Jira.HoursPerDay
+	}
+	if c.Jira.HoursPerWeek > 0 {
+		return c.Jira.HoursPerWeek / 5
+	}
+	return DefaultHoursPerDay
+}
+
 // DefaultConfig returns the default config
 func DefaultConfig() (config ChronosConfig) {
 	config = ChronosConfig{
@@ -90,6 +181,11 @@ func DefaultConfig() (config ChronosConfig) {
 			Username:      DefaultUsername,
 			WeeksLookback: DefaultWeeksLookback,
 			HoursPerWeek:  DefaultHoursPerWeek,
+			Concurrency:   DefaultConcurrency,
+		},
+		Report: Report{
+			CSVColumns: DefaultCSVColumns,
+			GroupBy:    DefaultGroupBy,
 		},
 	}
 	return
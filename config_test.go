@@ -1,6 +1,7 @@
 package main
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
@@ -20,3 +21,13 @@ func TestGenerateAndReadBack(t *testing.T) {
 		t.Errorf("Config URL is wrong, got: %s, want: %s.", config.Jira.URL, DefaultURL)
 	}
 }
+
+func TestReadConfigWithBadTimeZone(t *testing.T) {
+	configFile := filepath.Join(os.TempDir(), "chronos-timezone.yaml")
+	ioutil.WriteFile(configFile, []byte("jira:\n  timezone: Nowhere/Special\n"), 0600)
+
+	_, err := ReadConfigFile(configFile)
+	if err == nil {
+		t.Errorf("Expected an error for an unknown time zone")
+	}
+}
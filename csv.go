@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// csvColumns maps the column names users can pick to their values
+var csvColumns = map[string]func(TimeEntry) string{
+	"date":    func(e TimeEntry) string { return e.Date },
+	"week":    func(e TimeEntry) string { return e.Week.String() },
+	"issue":   func(e TimeEntry) string { return e.Issue },
+	"summary": func(e TimeEntry) string { return e.Summary },
+	"hours":   func(e TimeEntry) string { return fmt.Sprintf("%.2f", e.Hours) },
+	"comment": func(e TimeEntry) string { return e.Comment },
+	"author":  timeEntryAuthor,
+	"project": timeEntryProject,
+	"epic":    func(e TimeEntry) string { return e.Epic },
+	"worklog": func(e TimeEntry) string { return e.WorklogID },
+}
+
+func timeEntryAuthor(entry TimeEntry) string {
+	if entry.Employee != "" {
+		return entry.Employee
+	}
+	return entry.EmailAddress
+}
+
+// timeEntryProject is the project of the issue, cached
+// entries from before projects were fetched have none
+func timeEntryProject(entry TimeEntry) string {
+	if entry.Project != "" {
+		return entry.Project
+	}
+	return projectKey(entry.Issue)
+}
+
+// projectKey is the part of the issue key before the dash, e.g, AA for AA-1234
+func projectKey(issue string) string {
+	if i := strings.LastIndex(issue, "-"); i > 0 {
+		return issue[:i]
+	}
+	return issue
+}
+
+// ValidateCSVColumns makes sure all columns are known
+func ValidateCSVColumns(columns []string) error {
+	for _, column := range columns {
+		if _, ok := csvColumns[column]; !ok {
+			return fmt.Errorf("unknown csv column %s", column)
+		}
+	}
+	return nil
+}
+
+// sortTimeEntries orders by date and issue, the same way as the report
+func sortTimeEntries(timeEntries []TimeEntry) {
+	sort.SliceStable(timeEntries, func(i, j int) bool {
+		if timeEntries[i].Date == timeEntries[j].Date {
+			return timeEntries[i].Issue < timeEntries[j].Issue
+		}
+		return timeEntries[i].Date < timeEntries[j].Date
+	})
+}
+
+// aggregateTimeEntries merges all worklogs by the same author on the
+// same issue and day into one entry, so a team export keeps one row per
+// person. Comments are kept, one per line, and the worklog
+// IDs are separated by commas.
+func aggregateTimeEntries(timeEntries []TimeEntry) (aggregated []TimeEntry) {
+	index := make(map[string]int)
+	for _, entry := range timeEntries {
+		key := entry.Date + "|" + entry.Issue + "|" + entry.AuthorAccountID + "|" + timeEntryAuthor(entry)
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(aggregated)
+			aggregated = append(aggregated, entry)
+			continue
+		}
+
+		aggregated[i].Hours += entry.Hours
+		aggregated[i].WorklogID += "," + entry.WorklogID
+		if entry.Comment != "" {
+			if aggregated[i].Comment != "" {
+				aggregated[i].Comment += "\n"
+			}
+			aggregated[i].Comment += entry.Comment
+		}
+	}
+	return
+}
+
+// WriteCSV writes the time entries as RFC 4180 CSV with a header row
+func WriteCSV(w io.Writer, timeEntries []TimeEntry, columns []string, aggregate bool) error {
+	err := ValidateCSVColumns(columns)
+	if err != nil {
+		return err
+	}
+
+	sorted := make([]TimeEntry, len(timeEntries))
+	copy(sorted, timeEntries)
+	sortTimeEntries(sorted)
+
+	if aggregate {
+		sorted = aggregateTimeEntries(sorted)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.UseCRLF = true
+
+	err = writer.Write(columns)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range sorted {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = csvColumns[column](entry)
+		}
+
+		err = writer.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// PrintCSV prints the time entries as CSV
+func PrintCSV(timeEntries []TimeEntry, config ChronosConfig) {
+	var out bytes.Buffer
+	err := WriteCSV(&out, timeEntries, config.CSVColumns, config.CSVAggregate)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	fmt.Print(out.String())
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func helpWriteCSV(t *testing.T, timeEntries []TimeEntry, columns []string, aggregate bool, goldenFilename string) (output, expected string) {
+	var out bytes.Buffer
+	err := WriteCSV(&out, timeEntries, columns, aggregate)
+	if err != nil {
+		t.Fatalf("Unable to write csv %s", err)
+	}
+
+	expectedBytes, _ := ioutil.ReadFile(filepath.Join("testdata", goldenFilename))
+	return out.String(), string(expectedBytes)
+}
+
+func TestWriteCSV1234(t *testing.T) {
+	timeEntries := []TimeEntry{timeEntry4, timeEntry3, timeEntry2, timeEntry1}
+	output, expected := helpWriteCSV(t, timeEntries, DefaultCSVColumns, false, "timeEntry1234.csv")
+
+	if output != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output, expected)
+	}
+}
+
+func TestWriteCSVAggregated(t *testing.T) {
+	timeEntries := []TimeEntry{timeEntry1, timeEntry2, timeEntry1}
+	output, expected := helpWriteCSV(t, timeEntries, []string{"date", "issue", "hours", "comment"}, true, "timeEntry121-aggregated.csv")
+
+	if output != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output, expected)
+	}
+}
+
+func TestWriteCSVAggregatedPerAuthor(t *testing.T) {
+	alice, bob, alsoAlice := timeEntry1, timeEntry1, timeEntry1
+	alice.Employee, alice.Hours = "alice", 1
+	bob.Employee, bob.Hours = "bob", 2
+	alsoAlice.Employee, alsoAlice.Hours = "alice", 0.5
+
+	var out bytes.Buffer
+	WriteCSV(&out, []TimeEntry{alice, bob, alsoAlice}, []string{"author", "hours"}, true)
+
+	expected := "author,hours\r\nalice,1.50\r\nbob,2.00\r\n"
+	if out.String() != expected {
+		t.Errorf("Wrong aggregation, got: %q, want: %q.", out.String(), expected)
+	}
+}
+
+func TestWriteCSVQuoting(t *testing.T) {
+	entry := timeEntry1
+	entry.Comment = "Fixed \"it\", finally"
+
+	var out bytes.Buffer
+	WriteCSV(&out, []TimeEntry{entry}, []string{"issue", "comment"}, false)
+
+	expected := "issue,comment\r\nAA-1234,\"Fixed \"\"it\"\", finally\"\r\n"
+	if out.String() != expected {
+		t.Errorf("Wrong quoting, got: %q, want: %q.", out.String(), expected)
+	}
+}
+
+func TestWriteCSVWorklogIDs(t *testing.T) {
+	first, second := timeEntry1, timeEntry1
+	first.WorklogID = "10100"
+	second.WorklogID = "10101"
+
+	var out bytes.Buffer
+	WriteCSV(&out, []TimeEntry{first, second}, []string{"issue", "worklog"}, true)
+
+	expected := "issue,worklog\r\nAA-1234,\"10100,10101\"\r\n"
+	if out.String() != expected {
+		t.Errorf("Wrong worklog IDs, got: %q, want: %q.", out.String(), expected)
+	}
+}
+
+func TestWriteCSVUnknownColumn(t *testing.T) {
+	var out bytes.Buffer
+	err := WriteCSV(&out, []TimeEntry{timeEntry1}, []string{"date", "mood"}, false)
+
+	if err == nil {
+		t.Errorf("Expected an error for an unknown column")
+	}
+}
+
+func TestProjectKey(t *testing.T) {
+	if key := projectKey("AA-1234"); key != "AA" {
+		t.Errorf("Wrong project key, got: %s, want: %s.", key, "AA")
+	}
+}
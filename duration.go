@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// durationPart is one number and unit of a duration, e.g, 1.5h
+	durationPart = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([dhm])`)
+	// clockRange is a range of the day, e.g, 09:00-10:30
+	clockRange = regexp.MustCompile(`^(\d{1,2}):(\d{2})\s*-\s*(\d{1,2}):(\d{2})$`)
+)
+
+// A TimeSpent is how long someone worked, and for a range
+// like 09:00-10:30 also at what time of the day they started
+type TimeSpent struct {
+	Duration time.Duration
+	// From and To are the times of the day the range started and ended
+	From    time.Duration
+	To      time.Duration
+	IsRange bool
+}
+
+// On is the time spent by work that started at started. A range is
+// read on the clock of that day, so it is an hour shorter or longer
+// across a change to or from daylight saving time.
+func (s TimeSpent) On(started time.Time) time.Duration {
+	if !s.IsRange || started.IsZero() {
+		return s.Duration
+	}
+	return atClock(started, s.To).Sub(atClock(started, s.From))
+}
+
+// clock parses the hours and minutes of a time of the day
+func clock(hours, minutes string) (time.Duration, error) {
+	h, _ := strconv.Atoi(hours)
+	m, _ := strconv.Atoi(minutes)
+	if h > 23 || m > 59 {
+		return 0, fmt.Errorf("%s:%s is not a time of the day", hours, minutes)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// ParseTimeSpent reads durations like 1h30m, 1.5h, 90m, 1d or 1d 2h,
+// where a day is hoursPerDay long, and ranges like 09:00-10:30.
+// Jira counts in minutes, so the duration is rounded to minutes.
+func ParseTimeSpent(text string, hoursPerDay float64) (TimeSpent, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return TimeSpent{}, fmt.Errorf("no time given, e.g, 1h30m or 09:00-10:30")
+	}
+
+	if match := clockRange.FindStringSubmatch(text); match != nil {
+		from, err := clock(match[1], match[2])
+		if err != nil {
+			return TimeSpent{}, err
+		}
+		to, err := clock(match[3], match[4])
+		if err != nil {
+			return TimeSpent{}, err
+		}
+		if to <= from {
+			return TimeSpent{}, fmt.Errorf("%s ends before it starts", text)
+		}
+		return TimeSpent{Duration: to - from, From: from, To: to, IsRange: true}, nil
+	}
+
+	var minutes float64
+	for rest := text; rest != ""; rest = strings.TrimSpace(rest) {
+		match := durationPart.FindStringSubmatch(rest)
+		if match == nil {
+			return TimeSpent{}, fmt.Errorf("unable to read %q in %q, use e.g, 1h30m, 1.5h, 90m, 1d or 09:00-10:30", rest, text)
+		}
+		number, _ := strconv.ParseFloat(match[1], 64)
+		switch match[2] {
+		case "d":
+			if hoursPerDay <= 0 {
+				return TimeSpent{}, fmt.Errorf("unable to log days without hours per day in the config")
+			}
+			minutes += number * hoursPerDay * 60
+		case "h":
+			minutes += number * 60
+		case "m":
+			minutes += number
+		}
+		rest = rest[len(match[0]):]
+	}
+
+	rounded := math.Round(minutes)
+	if rounded < 1 {
+		return TimeSpent{}, fmt.Errorf("%s is less than a minute", text)
+	}
+	return TimeSpent{Duration: time.Duration(rounded) * time.Minute}, nil
+}
+
+// splitDuration splits a duration into whole hours and minutes
+func splitDuration(duration time.Duration) (hours, minutes int) {
+	total := int(duration / time.Minute)
+	return total / 60, total % 60
+}
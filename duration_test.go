@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeSpent(t *testing.T) {
+	cases := []struct {
+		text string
+		want time.Duration
+	}{
+		{"1h30m", 90 * time.Minute},
+		{"1h 30m", 90 * time.Minute},
+		{"1.5h", 90 * time.Minute},
+		{"90m", 90 * time.Minute},
+		{"1d", 8 * time.Hour},
+		{"1d 2h", 10 * time.Hour},
+		{"0.25h", 15 * time.Minute},
+		{"2H", 2 * time.Hour},
+	}
+
+	for _, c := range cases {
+		spent, err := ParseTimeSpent(c.text, 8)
+		if err != nil {
+			t.Fatalf("Unable to parse %s: %s", c.text, err)
+		}
+		if spent.Duration != c.want || spent.IsRange {
+			t.Errorf("Wrong time for %s, got: %s, want: %s.", c.text, spent.Duration, c.want)
+		}
+	}
+}
+
+func TestParseTimeSpentRange(t *testing.T) {
+	spent, err := ParseTimeSpent("09:00-10:30", 8)
+	if err != nil {
+		t.Fatalf("Unable to parse range: %s", err)
+	}
+	if spent.Duration != 90*time.Minute || spent.From != 9*time.Hour || !spent.IsRange {
+		t.Errorf("Wrong range, got: %s from %s.", spent.Duration, spent.From)
+	}
+}
+
+func TestParseTimeSpentErrors(t *testing.T) {
+	for _, text := range []string{"", "90", "1x", "1h foo", "0m", "10s", "10:30-09:00", "25:00-26:00"} {
+		if _, err := ParseTimeSpent(text, 8); err == nil {
+			t.Errorf("%q should fail", text)
+		}
+	}
+
+	if _, err := ParseTimeSpent("1d", 0); err == nil {
+		t.Errorf("Days without hours per day should fail")
+	}
+}
+
+func TestSplitDuration(t *testing.T) {
+	hours, minutes := splitDuration(150 * time.Minute)
+	if hours != 2 || minutes != 30 {
+		t.Errorf("Wrong split, got: %dh %dm, want: 2h 30m.", hours, minutes)
+	}
+}
+
+func TestDayLength(t *testing.T) {
+	config := DefaultConfig()
+	config.Jira.HoursPerWeek = 40
+	if length := config.DayLength(); length != 8 {
+		t.Errorf("Wrong day length, got: %.2f, want: 8.00.", length)
+	}
+
+	config.Jira.HoursPerDay = 6
+	if length := config.DayLength(); length != 6 {
+		t.Errorf("Wrong day length, got: %.2f, want: 6.00.", length)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// ErrorKind classifies what went wrong when talking to Jira
+type ErrorKind int
+
+const (
+	// UnknownError is anything we can not classify, e.g, network problems
+	UnknownError ErrorKind = iota
+	// AuthError means Jira did not accept our mail and api key
+	AuthError
+	// NotFoundError means the issue (or worklog) does not exist
+	NotFoundError
+	// RateLimitError means Jira kept rejecting us with 429
+	RateLimitError
+	// PartialError means some, but not all, results could be fetched
+	PartialError
+)
+
+// Exit codes used by main, one per error kind
+const (
+	exitUnknown   = 1
+	exitAuth      = 2
+	exitNotFound  = 3
+	exitRateLimit = 4
+	exitPartial   = 5
+)
+
+// A JiraError is a failed Jira request together with its classification
+type JiraError struct {
+	Kind ErrorKind
+	Op   string
+	Err  error
+}
+
+func (e *JiraError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Op, e.Err)
+}
+
+// A PartialResultError is returned together with the results that could
+// be fetched. Failed holds the issues we were unable to fetch.
+type PartialResultError struct {
+	Failed []string
+	Err    error
+}
+
+func (e *PartialResultError) Error() string {
+	return fmt.Sprintf("unable to fetch worklogs for %s: %s", strings.Join(e.Failed, ", "), e.Err)
+}
+
+// classifyError turns an error from go-jira into a JiraError
+// by looking at the status code of the response
+func classifyError(op string, resp *jira.Response, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(*JiraError); ok {
+		return err
+	}
+
+	kind := UnknownError
+	if resp != nil && resp.Response != nil {
+		switch resp.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			kind = AuthError
+		case http.StatusNotFound:
+			kind = NotFoundError
+		case http.StatusTooManyRequests:
+			kind = RateLimitError
+		}
+	}
+
+	return &JiraError{Kind: kind, Op: op, Err: err}
+}
+
+// errorKind returns the kind of any error, unknown if it is not ours
+func errorKind(err error) ErrorKind {
+	switch e := err.(type) {
+	case *JiraError:
+		return e.Kind
+	case *PartialResultError:
+		return PartialError
+	}
+	return UnknownError
+}
+
+// ErrorHint is a helpful message for the user on how to fix the error
+func ErrorHint(err error) string {
+	switch errorKind(err) {
+	case AuthError:
+		return "JIRA rejected your credentials, check mail and apikey in chronos.yaml"
+	case NotFoundError:
+		return "JIRA could not find the issue, check the issue key and your permissions"
+	case RateLimitError:
+		return "JIRA is rate limiting you, try again later or lower concurrency in chronos.yaml"
+	case PartialError:
+		return "Some worklogs could not be fetched, the report is incomplete"
+	}
+	return "Unable to talk to JIRA, check the url in chronos.yaml and your network"
+}
+
+// ExitCode is the process exit code for an error
+func ExitCode(err error) int {
+	switch errorKind(err) {
+	case AuthError:
+		return exitAuth
+	case NotFoundError:
+		return exitNotFound
+	case RateLimitError:
+		return exitRateLimit
+	case PartialError:
+		return exitPartial
+	}
+	return exitUnknown
+}
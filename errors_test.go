@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func responseWithStatus(status int) *jira.Response {
+	return &jira.Response{Response: &http.Response{StatusCode: status}}
+}
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		resp *jira.Response
+		kind ErrorKind
+		code int
+	}{
+		{responseWithStatus(http.StatusUnauthorized), AuthError, exitAuth},
+		{responseWithStatus(http.StatusForbidden), AuthError, exitAuth},
+		{responseWithStatus(http.StatusNotFound), NotFoundError, exitNotFound},
+		{responseWithStatus(http.StatusTooManyRequests), RateLimitError, exitRateLimit},
+		{responseWithStatus(http.StatusInternalServerError), UnknownError, exitUnknown},
+		{nil, UnknownError, exitUnknown},
+	}
+
+	for _, c := range cases {
+		err := classifyError("search", c.resp, errors.New("failed"))
+		if errorKind(err) != c.kind {
+			t.Errorf("Wrong kind, got: %d, want: %d.", errorKind(err), c.kind)
+		}
+		if ExitCode(err) != c.code {
+			t.Errorf("Wrong exit code, got: %d, want: %d.", ExitCode(err), c.code)
+		}
+	}
+}
+
+func TestClassifyErrorKeepsClassification(t *testing.T) {
+	err := classifyError("search", responseWithStatus(http.StatusNotFound), errors.New("failed"))
+	again := classifyError("search", nil, err)
+
+	if errorKind(again) != NotFoundError {
+		t.Errorf("Classification was lost, got: %d, want: %d.", errorKind(again), NotFoundError)
+	}
+}
+
+func TestPartialResultExitCode(t *testing.T) {
+	err := &PartialResultError{Failed: []string{"AA-1"}, Err: errors.New("gone")}
+
+	if ExitCode(err) != exitPartial {
+		t.Errorf("Wrong exit code, got: %d, want: %d.", ExitCode(err), exitPartial)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// maxRateLimitRetries is how many times we retry a request
+// that Jira rejected with 429 Too Many Requests
+const maxRateLimitRetries = 5
+
+// worklogGetter is the part of the Jira client we need to fetch worklogs.
+// It is satisfied by jiraWorklogs and makes the worker pool testable.
+type worklogGetter interface {
+	Worklogs(ctx context.Context, issueID string) ([]jira.WorklogRecord, *jira.Response, error)
+}
+
+// doWorklogRequest asks REST v3 and decodes the answer into v. Jira
+// Server and Data Center only have v2 and answer 404 on v3, so then
+// the request is made again on v2, where the comments are plain text.
+func doWorklogRequest(ctx context.Context, client *jira.Client, method, path string, body, v interface{}) (resp *jira.Response, err error) {
+	for _, version := range []string{"3", "2"} {
+		var req *http.Request
+		req, err = client.NewRequestWithContext(ctx, method, fmt.Sprintf("rest/api/%s/%s", version, path), body)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err = client.Do(req, v)
+		if err == nil || resp == nil || resp.StatusCode != http.StatusNotFound {
+			return resp, err
+		}
+	}
+	return resp, err
+}
+
+// jiraWorklogs fetches worklogs through REST v3. go-jira uses v2,
+// where Jira Cloud hides the ADF comments behind their rendering.
+type jiraWorklogs struct {
+	client *jira.Client
+}
+
+func (w jiraWorklogs) Worklogs(ctx context.Context, issueID string) ([]jira.WorklogRecord, *jira.Response, error) {
+	var page struct {
+		Worklogs []worklogRecord `json:"worklogs"`
+	}
+	resp, err := doWorklogRequest(ctx, w.client, "GET", fmt.Sprintf("issue/%s/worklog", issueID), nil, &page)
+	if err != nil {
+		return nil, resp, err
+	}
+	return worklogRecords(page.Worklogs), resp, nil
+}
+
+func rateLimited(resp *jira.Response) bool {
+	return resp != nil && resp.Response != nil && resp.StatusCode == http.StatusTooManyRequests
+}
+
+// retryAfter reads the delay Jira asks for. The header is given in
+// seconds; if it is missing we back off linearly on our own.
+func retryAfter(resp *jira.Response, attempt int) time.Duration {
+	if resp != nil && resp.Response != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return time.Duration(attempt+1) * time.Second
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func fetchWorklogsForIssue(ctx context.Context, getter worklogGetter, issue jira.Issue, config ChronosConfig) ([]TimeEntry, error) {
+	for attempt := 0; ; attempt++ {
+		records, resp, err := getter.Worklogs(ctx, issue.Key)
+		if err == nil {
+			var timeEntries []TimeEntry
+			for _, worklogRecord := range records {
+				timeEntries = append(timeEntries, issueAndWorklogToTimeEntry(issue, worklogRecord, config))
+			}
+			return timeEntries, nil
+		}
+
+		if !rateLimited(resp) || attempt >= maxRateLimitRetries {
+			return nil, classifyError(fmt.Sprintf("worklog for %s", issue.Key), resp, err)
+		}
+
+		if resp.Body != nil {
+			resp.Body.Close()
+		}
+
+		if err := sleepContext(ctx, retryAfter(resp, attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// extractAllWorklogsForIssues fetches the worklogs of every issue using
+// a pool of config.Jira.Concurrency workers. The time entries are returned
+// in the same order as the issues, no matter which request finishes first.
+// The first hard failure cancels all outstanding requests. Issues that
+// have disappeared are skipped and reported with a PartialResultError.
+func extractAllWorklogsForIssues(ctx context.Context, getter worklogGetter, issues []jira.Issue, config ChronosConfig) ([]TimeEntry, error) {
+	concurrency := config.Jira.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]TimeEntry, len(issues))
+	missing := make([]error, len(issues))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				timeEntries, err := fetchWorklogsForIssue(ctx, getter, issues[i], config)
+				if errorKind(err) == NotFoundError {
+					missing[i] = err
+					continue
+				}
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				results[i] = timeEntries
+			}
+		}()
+	}
+
+feed:
+	for i := range issues {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var timeEntries []TimeEntry
+	for _, result := range results {
+		timeEntries = append(timeEntries, result...)
+	}
+
+	var partial *PartialResultError
+	for i, err := range missing {
+		if err == nil {
+			continue
+		}
+		if partial == nil {
+			partial = &PartialResultError{Err: err}
+		}
+		partial.Failed = append(partial.Failed, issues[i].Key)
+	}
+
+	if partial != nil {
+		return timeEntries, partial
+	}
+	return timeEntries, nil
+}
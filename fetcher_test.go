@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+var worklogCreated = jira.Time(time.Date(2020, 1, 8, 10, 0, 0, 0, time.UTC))
+
+// fakeWorklogGetter answers with one worklog per issue, where the
+// hours are taken from the issue key. Earlier issues answer slower
+// to shake out any ordering bugs.
+type fakeWorklogGetter struct {
+	hours       map[string]int
+	failIssue   string
+	gone        string
+	rateLimited int32
+	calls       int32
+}
+
+func (f *fakeWorklogGetter) Worklogs(ctx context.Context, issueID string) ([]jira.WorklogRecord, *jira.Response, error) {
+	atomic.AddInt32(&f.calls, 1)
+
+	if issueID == f.failIssue {
+		return nil, nil, errors.New("boom")
+	}
+
+	if issueID == f.gone {
+		resp := &jira.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
+		return nil, resp, errors.New("404")
+	}
+
+	if atomic.AddInt32(&f.rateLimited, -1) >= 0 {
+		header := http.Header{}
+		header.Set("Retry-After", "0")
+		resp := &jira.Response{Response: &http.Response{StatusCode: http.StatusTooManyRequests, Header: header}}
+		return nil, resp, errors.New("429")
+	}
+
+	hours := f.hours[issueID]
+	select {
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	case <-time.After(time.Duration(10-hours) * time.Millisecond):
+	}
+
+	record := jira.WorklogRecord{
+		Author:           &jira.User{Name: "maxx"},
+		Created:          &worklogCreated,
+		Started:          &worklogCreated,
+		TimeSpentSeconds: hours * 3600,
+	}
+	return []jira.WorklogRecord{record}, nil, nil
+}
+
+func fetcherIssues() (issues []jira.Issue) {
+	for _, key := range []string{"AA-1", "AA-2", "AA-3", "AA-4", "AA-5"} {
+		issues = append(issues, jira.Issue{Key: key, Fields: &jira.IssueFields{}})
+	}
+	return
+}
+
+func fetcherConfig(concurrency int) ChronosConfig {
+	config := DefaultConfig()
+	config.Concurrency = concurrency
+	return config
+}
+
+func TestExtractAllWorklogsKeepsOrder(t *testing.T) {
+	getter := &fakeWorklogGetter{hours: map[string]int{"AA-1": 1, "AA-2": 2, "AA-3": 3, "AA-4": 4, "AA-5": 5}}
+
+	timeEntries, err := extractAllWorklogsForIssues(context.Background(), getter, fetcherIssues(), fetcherConfig(3))
+	if err != nil {
+		t.Errorf("Unexpected error %s", err)
+	}
+
+	if len(timeEntries) != 5 {
+		t.Fatalf("Wrong number of time entries, got: %d, want: %d.", len(timeEntries), 5)
+	}
+
+	for i, entry := range timeEntries {
+		if entry.Issue != fetcherIssues()[i].Key {
+			t.Errorf("Wrong order, got: %s, want: %s.", entry.Issue, fetcherIssues()[i].Key)
+		}
+	}
+}
+
+func TestExtractAllWorklogsFirstFailure(t *testing.T) {
+	getter := &fakeWorklogGetter{failIssue: "AA-2"}
+
+	timeEntries, err := extractAllWorklogsForIssues(context.Background(), getter, fetcherIssues(), fetcherConfig(1))
+	if err == nil {
+		t.Errorf("Expected an error")
+	}
+
+	if len(timeEntries) != 0 {
+		t.Errorf("Expected no time entries, got: %d.", len(timeEntries))
+	}
+
+	if calls := atomic.LoadInt32(&getter.calls); calls != 2 {
+		t.Errorf("Requests were not cancelled, got: %d calls, want: %d.", calls, 2)
+	}
+}
+
+func TestExtractAllWorklogsPartial(t *testing.T) {
+	getter := &fakeWorklogGetter{hours: map[string]int{"AA-1": 1, "AA-3": 3, "AA-4": 4, "AA-5": 5}, gone: "AA-2"}
+
+	timeEntries, err := extractAllWorklogsForIssues(context.Background(), getter, fetcherIssues(), fetcherConfig(2))
+
+	partial, ok := err.(*PartialResultError)
+	if !ok {
+		t.Fatalf("Expected a partial result error, got: %v", err)
+	}
+
+	if len(partial.Failed) != 1 || partial.Failed[0] != "AA-2" {
+		t.Errorf("Wrong failed issues, got: %v, want: %v.", partial.Failed, []string{"AA-2"})
+	}
+
+	if len(timeEntries) != 4 {
+		t.Errorf("Wrong number of time entries, got: %d, want: %d.", len(timeEntries), 4)
+	}
+}
+
+func TestExtractAllWorklogsRetriesRateLimit(t *testing.T) {
+	getter := &fakeWorklogGetter{hours: map[string]int{"AA-1": 1}, rateLimited: 2}
+
+	timeEntries, err := extractAllWorklogsForIssues(context.Background(), getter, fetcherIssues()[:1], fetcherConfig(1))
+	if err != nil {
+		t.Errorf("Unexpected error %s", err)
+	}
+
+	if len(timeEntries) != 1 || atomic.LoadInt32(&getter.calls) != 3 {
+		t.Errorf("Expected one time entry after two retries, got: %d entries, %d calls.", len(timeEntries), getter.calls)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	header := http.Header{}
+	header.Set("Retry-After", "7")
+	resp := &jira.Response{Response: &http.Response{StatusCode: http.StatusTooManyRequests, Header: header}}
+
+	if d := retryAfter(resp, 0); d != 7*time.Second {
+		t.Errorf("Wrong delay, got: %s, want: %s.", d, 7*time.Second)
+	}
+
+	if d := retryAfter(nil, 2); d != 3*time.Second {
+		t.Errorf("Wrong fallback delay, got: %s, want: %s.", d, 3*time.Second)
+	}
+}
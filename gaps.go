@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// A Gap is an expected work day with less time logged than expected
+type Gap struct {
+	Date     string
+	Week     ISOWeek
+	Expected float32
+	Logged   float32
+}
+
+// Missing is the time still to be logged on the day
+func (g Gap) Missing() float32 {
+	return g.Expected - g.Logged
+}
+
+// FindGaps walks every day from the from date up to the Until
+// date of the calendar and returns the days that are short of
+// the expected hours. Weekends and days off expect nothing.
+func FindGaps(timeEntries []TimeEntry, calendar *WorkCalendar, from string) (gaps []Gap) {
+	if !calendar.Enabled() {
+		return
+	}
+
+	logged := make(map[string]float32)
+	for _, entry := range timeEntries {
+		logged[entry.Date] += entry.Hours
+	}
+
+	day, err := time.Parse(dateLayout, from)
+	if err != nil {
+		return
+	}
+
+	for ; day.Format(dateLayout) <= calendar.Until; day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		expected := calendar.Expected(date)
+		// Compare in hundredths, as the report does
+		if expected == 0 || int(logged[date]*100+0.5) >= int(expected*100+0.5) {
+			continue
+		}
+		gaps = append(gaps, Gap{Date: date, Week: ISOWeekOf(day), Expected: expected, Logged: logged[date]})
+	}
+	return
+}
+
+// PrettyPrintGaps lists the days and hours still to be logged
+func PrettyPrintGaps(gaps []Gap) (out bytes.Buffer) {
+	if len(gaps) == 0 {
+		out.WriteString("No gaps, all expected hours are logged\n")
+		return
+	}
+
+	var total float32 = 0.0
+	for _, gap := range gaps {
+		total += gap.Missing()
+		out.WriteString(fmt.Sprintf("%s: %6.2f of %6.2f to log\n", gap.Date, gap.Missing(), gap.Expected))
+	}
+	out.WriteString("\t------------------\n")
+	out.WriteString(fmt.Sprintf("\tTotal:   %6.2f\n", total))
+	return
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func gapsCalendar() *WorkCalendar {
+	calendar := weeklyCalendar(5)
+	calendar.Until = "2018-01-10"
+	return calendar
+}
+
+func TestFindGaps(t *testing.T) {
+	timeEntries := []TimeEntry{timeEntry1, timeEntry2, timeEntry3, timeEntry4}
+	gaps := FindGaps(timeEntries, gapsCalendar(), "2018-01-01")
+
+	// Tuesday to Friday in week 1 and Wednesday in week 2, not the weekend
+	expected := []string{"2018-01-02", "2018-01-03", "2018-01-04", "2018-01-05", "2018-01-10"}
+	if len(gaps) != len(expected) {
+		t.Fatalf("Wrong number of gaps, got: %d, want: %d.", len(gaps), len(expected))
+	}
+
+	for i, gap := range gaps {
+		if gap.Date != expected[i] || gap.Missing() != 1 {
+			t.Errorf("Wrong gap, got: %s %.2f, want: %s %.2f.", gap.Date, gap.Missing(), expected[i], 1.0)
+		}
+	}
+
+	if gaps[4].Week != (ISOWeek{2018, 2}) {
+		t.Errorf("Wrong week, got: %s, want: %s.", gaps[4].Week, "2018-W02")
+	}
+}
+
+func TestFindGapsPartialDay(t *testing.T) {
+	halfDay := timeEntry1
+	halfDay.Hours = 0.5
+
+	calendar := gapsCalendar()
+	calendar.Until = "2018-01-01"
+	gaps := FindGaps([]TimeEntry{halfDay}, calendar, "2018-01-01")
+
+	if len(gaps) != 1 || gaps[0].Logged != 0.5 || gaps[0].Missing() != 0.5 {
+		t.Errorf("Wrong gaps for a half day, got: %+v.", gaps)
+	}
+}
+
+func TestFindGapsDayOff(t *testing.T) {
+	calendar := gapsCalendar()
+	calendar.daysOff["2018-01-03"] = true
+	gaps := FindGaps([]TimeEntry{}, calendar, "2018-01-03")
+
+	if len(gaps) != 5 || gaps[0].Date != "2018-01-04" {
+		t.Errorf("Days off are not gaps, got: %+v.", gaps)
+	}
+}
+
+func TestFindGapsWithoutCalendar(t *testing.T) {
+	gaps := FindGaps([]TimeEntry{}, weeklyCalendar(0), "2018-01-01")
+	if len(gaps) != 0 {
+		t.Errorf("Nothing is missing without expected hours, got: %d gaps.", len(gaps))
+	}
+}
+
+func TestPrettyPrintGaps(t *testing.T) {
+	timeEntries := []TimeEntry{timeEntry1, timeEntry2, timeEntry3, timeEntry4}
+	output := PrettyPrintGaps(FindGaps(timeEntries, gapsCalendar(), "2018-01-08"))
+
+	expected := "2018-01-10:   1.00 of   1.00 to log\n" +
+		"\t------------------\n" +
+		"\tTotal:     1.00\n"
+	if output.String() != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output.String(), expected)
+	}
+}
+
+func TestPrettyPrintWithGaps(t *testing.T) {
+	timeEntries := []TimeEntry{timeEntry1, timeEntry2, timeEntry3, timeEntry4}
+	calendar := gapsCalendar()
+	commands := BuildCommandsWithGaps(timeEntries, FindGaps(timeEntries, calendar, "2018-01-01"))
+	outputBuffer := PrettyPrintWithOptions(commands, TextOptions{Legend: true, Calendar: calendar})
+
+	expectedBytes, _ := ioutil.ReadFile(filepath.Join("testdata", "timeEntry1234-gaps.txt"))
+	output := strings.TrimRight(outputBuffer.String(), "\n")
+	expected := strings.TrimRight(string(expectedBytes), "\n")
+
+	if output != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output, expected)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// GroupBys are the ways the report can be grouped with --group-by
+var GroupBys = []string{"day", "week", "month", "issue", "project"}
+
+// ValidGroupBy tells if the grouping is one of GroupBys
+func ValidGroupBy(groupBy string) bool {
+	for _, known := range GroupBys {
+		if groupBy == known {
+			return true
+		}
+	}
+	return false
+}
+
+// A section picks the section a time entry is reported under. The
+// key orders the sections and the title is printed above them.
+type section func(entry TimeEntry) (key, title string)
+
+// sections are the groupings besides weeks, which BuildCommands handles
+var sections = map[string]section{
+	// All days end up in one section, titled by the builder
+	"day": func(entry TimeEntry) (string, string) {
+		return "", ""
+	},
+	"month": func(entry TimeEntry) (string, string) {
+		month, err := time.Parse(dateLayout, entry.Date)
+		if err != nil {
+			return entry.Date, entry.Date
+		}
+		return month.Format("2006-01"), month.Format("January 2006")
+	},
+	"project": func(entry TimeEntry) (string, string) {
+		project := timeEntryProject(entry)
+		return project, "Project " + project
+	},
+	"issue": func(entry TimeEntry) (string, string) {
+		return entry.Issue, fmt.Sprintf("%s %s", entry.Issue, entry.Summary)
+	},
+}
+
+// BuildGroupedCommands is BuildCommands with other sections than
+// weeks. Within a section the dates are listed with their issues,
+// except when grouping by issue, where each date is a single line.
+func BuildGroupedCommands(timeEntries []TimeEntry, groupBy string) []Command {
+	return BuildGroupedCommandsWithGaps(timeEntries, nil, groupBy)
+}
+
+// BuildGroupedCommandsWithGaps is BuildGroupedCommands with the
+// placeholders of BuildCommandsWithGaps. A gap has no issue, so the
+// gaps are left out when grouping by issue or project.
+func BuildGroupedCommandsWithGaps(timeEntries []TimeEntry, gaps []Gap, groupBy string) []Command {
+	sectionOf, ok := sections[groupBy]
+	if !ok {
+		return BuildCommandsWithGaps(timeEntries, gaps)
+	}
+	if groupBy == "issue" || groupBy == "project" {
+		gaps = nil
+	}
+
+	group := grouping{
+		key: func(entry TimeEntry) string {
+			key, _ := sectionOf(entry)
+			return key
+		},
+		open: func(rows []TimeEntry) Command {
+			if groupBy == "day" {
+				return newSection{title: fmt.Sprintf("%s to %s", rows[0].Date, rows[len(rows)-1].Date)}
+			}
+			_, title := sectionOf(rows[0])
+			return newSection{title: title}
+		},
+		close:   []Command{summarySection{}, clearSection{}},
+		compact: groupBy == "issue",
+	}
+	return buildCommands(timeEntries, gaps, group)
+}
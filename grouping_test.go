@@ -0,0 +1,122 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func groupingEntries() []TimeEntry {
+	february := timeEntry4
+	february.Date = "2018-02-01"
+	february.Week = ISOWeek{2018, 5}
+
+	other := timeEntry3
+	other.Issue = "BB-1"
+	other.Summary = "Summary of issue C"
+
+	return []TimeEntry{timeEntry1, timeEntry2, other, february}
+}
+
+func helpGroupedPrettyPrint(t *testing.T, groupBy, goldenFilename string) {
+	commands := BuildGroupedCommands(groupingEntries(), groupBy)
+	outputBuffer := PrettyPrint(commands)
+
+	expectedBytes, _ := ioutil.ReadFile(filepath.Join("testdata", goldenFilename))
+	output := strings.TrimRight(outputBuffer.String(), "\n")
+	expected := strings.TrimRight(string(expectedBytes), "\n")
+
+	if output != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output, expected)
+	}
+}
+
+func TestGroupByMonth(t *testing.T) {
+	helpGroupedPrettyPrint(t, "month", "grouped-month.txt")
+}
+
+func TestGroupByIssue(t *testing.T) {
+	helpGroupedPrettyPrint(t, "issue", "grouped-issue.txt")
+}
+
+func TestGroupByProject(t *testing.T) {
+	helpGroupedPrettyPrint(t, "project", "grouped-project.txt")
+}
+
+func TestGroupByDay(t *testing.T) {
+	helpGroupedPrettyPrint(t, "day", "grouped-day.txt")
+}
+
+func TestGroupByMonthBrief(t *testing.T) {
+	output := PrettyPrintBrief(BuildGroupedCommands(groupingEntries(), "month"))
+
+	expected := "January 2018:   6.00\n" +
+		"February 2018:   4.00\n"
+	if output.String() != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output.String(), expected)
+	}
+}
+
+func TestGroupByWeekIsBuildCommands(t *testing.T) {
+	grouped := BuildGroupedCommands(groupingEntries(), "week")
+	commands := BuildCommands(groupingEntries())
+
+	if len(grouped) != len(commands) {
+		t.Errorf("Wrong number of commands, got: %d, want: %d.", len(grouped), len(commands))
+	}
+}
+
+func TestGroupByEmpty(t *testing.T) {
+	commands := BuildGroupedCommands([]TimeEntry{}, "month")
+	if len(commands) != 0 {
+		t.Errorf("Wrong number of commands, got: %d, want: %d.", len(commands), 0)
+	}
+}
+
+func TestValidGroupBy(t *testing.T) {
+	if !ValidGroupBy("month") || ValidGroupBy("year") {
+		t.Errorf("Wrong groupings")
+	}
+}
+
+func TestRenderMarkdownGroupByMonth(t *testing.T) {
+	output := markdownRenderer{}.Render(BuildGroupedCommands(groupingEntries(), "month"))
+
+	expectedBytes, _ := ioutil.ReadFile(filepath.Join("testdata", "grouped-month.md"))
+	if output.String() != string(expectedBytes) {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output.String(), string(expectedBytes))
+	}
+}
+
+func TestGroupByMonthWithGaps(t *testing.T) {
+	timeEntries := []TimeEntry{timeEntry1, timeEntry2, timeEntry3, timeEntry4}
+	gaps := FindGaps(timeEntries, gapsCalendar(), "2018-01-08")
+	output := PrettyPrint(BuildGroupedCommandsWithGaps(timeEntries, gaps, "month"))
+
+	expected := "2018-01-10\n\t!! gap:   1.00 not logged\n"
+	if !strings.Contains(output.String(), expected) {
+		t.Errorf("Wrong output, got:\n%s\nwant it to contain:\n%s\n", output.String(), expected)
+	}
+}
+
+func TestGroupByIssueWithoutGaps(t *testing.T) {
+	timeEntries := []TimeEntry{timeEntry1, timeEntry2, timeEntry3, timeEntry4}
+	gaps := FindGaps(timeEntries, gapsCalendar(), "2018-01-08")
+	output := PrettyPrint(BuildGroupedCommandsWithGaps(timeEntries, gaps, "issue"))
+
+	if strings.Contains(output.String(), "gap") {
+		t.Errorf("Gaps have no issue, got:\n%s", output.String())
+	}
+}
+
+func TestGroupByIssueComments(t *testing.T) {
+	commented := timeEntry1
+	commented.Comment = "Reviewed the login flow"
+	output := PrettyPrintWithOptions(BuildGroupedCommands([]TimeEntry{commented, timeEntry1}, "issue"), TextOptions{Comments: true})
+
+	expected := "\t2018-01-01:   2.00\n\t                   // Reviewed the login flow\n"
+	if !strings.Contains(output.String(), expected) {
+		t.Errorf("Wrong output, got:\n%s\nwant it to contain:\n%s\n", output.String(), expected)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// htmlRenderer prints a standalone HTML page with one table
+// per week and links back to the issues in Jira. The last
+// column is the worklog ID, for chronos worklog.
+type htmlRenderer struct {
+	baseURL string
+}
+
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Chronos report</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; }
+td.hours { text-align: right; }
+tr.total td { font-weight: bold; }
+</style>
+</head>
+<body>
+`
+
+const htmlFooter = `</body>
+</html>
+`
+
+func (r htmlRenderer) issueLink(issue string) string {
+	url := issueURL(r.baseURL, issue)
+	if url == "" {
+		return html.EscapeString(issue)
+	}
+	return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), html.EscapeString(issue))
+}
+
+func (r htmlRenderer) Render(commands []Command) (out bytes.Buffer) {
+	var sectionTotal float32 = 0.0
+	var weekTotal float32 = 0.0
+	var dateTotal float32 = 0.0
+	var issueHours float32 = 0.0
+
+	var week ISOWeek
+	var weekStarted bool = false
+	var date string = ""
+	var issue string = ""
+	var issueText string = ""
+	var worklogID string = ""
+
+	// The worklogs of a date, for a compact date
+	var dateWorklogs []string
+
+	// Only the first row of a date shows the date
+	var dateCell string = ""
+
+	out.WriteString(htmlHeader)
+
+	for _, command := range commands {
+		switch cmd := command.(type) {
+
+		case clearSection:
+			sectionTotal = 0.0
+		case clearWeek:
+			weekTotal = 0.0
+		case clearDate:
+			dateTotal = 0.0
+			dateWorklogs = nil
+		case clearIssue:
+			issueHours = 0.0
+
+		case newWeek:
+			week = cmd.week
+			weekStarted = true
+			out.WriteString(fmt.Sprintf("<h2>Week %s</h2>\n", week))
+			out.WriteString("<table>\n")
+			out.WriteString("<tr><th>Date</th><th>Issue</th><th>Hours</th><th>Summary</th><th>Worklog</th></tr>\n")
+
+		case newSection:
+			out.WriteString(fmt.Sprintf("<h2>%s</h2>\n", html.EscapeString(cmd.title)))
+			out.WriteString("<table>\n")
+			out.WriteString("<tr><th>Date</th><th>Issue</th><th>Hours</th><th>Summary</th><th>Worklog</th></tr>\n")
+
+		case summarySection:
+			out.WriteString(fmt.Sprintf("<tr class=\"total\"><td></td><td>Total</td><td class=\"hours\">%.2f</td><td></td><td></td></tr>\n", sectionTotal))
+			out.WriteString("</table>\n")
+
+		case printDateTotal:
+			out.WriteString(fmt.Sprintf("<tr><td>%s</td><td></td><td class=\"hours\">%.2f</td><td></td><td>%s</td></tr>\n",
+				html.EscapeString(cmd.date), dateTotal, html.EscapeString(strings.Join(dateWorklogs, ", "))))
+
+		case newDate:
+			date = cmd.date
+			dateCell = date
+
+		case newIssue:
+			issue = cmd.issue
+			issueText = cmd.summary
+
+		case summaryDate:
+			if date != "" {
+				out.WriteString(fmt.Sprintf("<tr class=\"total\"><td></td><td>Total</td><td class=\"hours\">%.2f</td><td></td><td></td></tr>\n", dateTotal))
+			}
+
+		case summaryWeek:
+			if weekStarted {
+				out.WriteString(fmt.Sprintf("<tr class=\"total\"><td></td><td>Week total</td><td class=\"hours\">%.2f</td><td></td><td></td></tr>\n", weekTotal))
+				out.WriteString("</table>\n")
+			}
+
+		case noteHours:
+			sectionTotal += cmd.hours
+			weekTotal += cmd.hours
+			dateTotal += cmd.hours
+			issueHours = cmd.hours
+			worklogID = cmd.worklogID
+			if worklogID != "" {
+				dateWorklogs = append(dateWorklogs, worklogID)
+			}
+
+		case printNewIssue:
+			out.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%s</td><td class=\"hours\">%.2f</td><td>%s</td><td>%s</td></tr>\n",
+				html.EscapeString(dateCell), r.issueLink(issue), issueHours, html.EscapeString(issueText), html.EscapeString(worklogID)))
+			dateCell = ""
+
+		case printSameIssue:
+			out.WriteString(fmt.Sprintf("<tr><td></td><td></td><td class=\"hours\">%.2f</td><td></td><td>%s</td></tr>\n", issueHours, html.EscapeString(worklogID)))
+		}
+	}
+
+	out.WriteString(htmlFooter)
+	return
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// selfGetter is the part of the Jira client we need to find ourselves
+type selfGetter interface {
+	GetSelf() (*jira.User, *jira.Response, error)
+}
+
+// myself is the account behind the API key, remembered between
+// runs so we do not have to ask Jira every time
+type myself struct {
+	URL       string `json:"url"`
+	Mail      string `json:"mail"`
+	AccountID string `json:"accountId"`
+}
+
+// MyselfFile is where the account of the current user is remembered
+func MyselfFile() (string, error) {
+	return chronosFile("myself.json")
+}
+
+// ResolveAccountID returns the account ID of the current user. A
+// configured account ID wins, otherwise we ask /myself once per
+// Jira instance and mail, and remember the answer in myselfFile.
+func ResolveAccountID(getter selfGetter, config ChronosConfig, myselfFile string) (string, error) {
+	if config.Jira.AccountID != "" {
+		return config.Jira.AccountID, nil
+	}
+
+	var remembered myself
+	if err := readJSONFile(myselfFile, &remembered); err == nil &&
+		remembered.URL == config.Jira.URL && remembered.Mail == config.Jira.Mail && remembered.AccountID != "" {
+		return remembered.AccountID, nil
+	}
+
+	user, resp, err := getter.GetSelf()
+	if err != nil {
+		return "", classifyError("myself", resp, err)
+	}
+
+	remembered = myself{URL: config.Jira.URL, Mail: config.Jira.Mail, AccountID: user.AccountID}
+	if err := writeJSONFile(myselfFile, &remembered); err != nil {
+		return "", err
+	}
+	return user.AccountID, nil
+}
+
+// sameUser tells if a user, as given in the config or on the command
+// line, is the author with the account ID, name and mail. Mails only
+// match in full or on the whole part before the @, so ann is not anna.
+func sameUser(user, accountID, name, mail string) bool {
+	if user == "" {
+		return false
+	}
+	if user == accountID || user == name || user == mail {
+		return true
+	}
+	at := strings.Index(mail, "@")
+	return at > 0 && mail[:at] == user
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+type fakeSelf struct {
+	accountID string
+	calls     int
+}
+
+func (f *fakeSelf) GetSelf() (*jira.User, *jira.Response, error) {
+	f.calls++
+	return &jira.User{AccountID: f.accountID}, nil, nil
+}
+
+func TestResolveAccountIDRemembers(t *testing.T) {
+	myselfFile := filepath.Join(os.TempDir(), "chronos-myself", "myself.json")
+	defer os.RemoveAll(filepath.Dir(myselfFile))
+
+	config := DefaultConfig()
+	getter := &fakeSelf{accountID: "5b10ac8d82e05b22cc7d4ef5"}
+
+	for i := 0; i < 2; i++ {
+		accountID, err := ResolveAccountID(getter, config, myselfFile)
+		if err != nil {
+			t.Fatalf("Unable to resolve account %s", err)
+		}
+		if accountID != getter.accountID {
+			t.Errorf("Wrong account, got: %s, want: %s.", accountID, getter.accountID)
+		}
+	}
+
+	if getter.calls != 1 {
+		t.Errorf("Wrong number of calls to /myself, got: %d, want: %d.", getter.calls, 1)
+	}
+
+	// Another Jira instance is another account
+	config.Jira.URL = "https://other.atlassian.net"
+	ResolveAccountID(getter, config, myselfFile)
+	if getter.calls != 2 {
+		t.Errorf("Wrong number of calls to /myself, got: %d, want: %d.", getter.calls, 2)
+	}
+}
+
+func TestResolveAccountIDConfigured(t *testing.T) {
+	config := DefaultConfig()
+	config.AccountID = "configured"
+	getter := &fakeSelf{accountID: "from-jira"}
+
+	accountID, _ := ResolveAccountID(getter, config, filepath.Join(os.TempDir(), "chronos-not-used.json"))
+	if accountID != "configured" || getter.calls != 0 {
+		t.Errorf("The configured account should win, got: %s after %d calls.", accountID, getter.calls)
+	}
+}
+
+func TestSameUser(t *testing.T) {
+	cases := []struct {
+		user, accountID, name, mail string
+		same                        bool
+	}{
+		{"ann", "", "", "ann@example.com", true},
+		{"ann", "", "", "anna@example.com", false},
+		{"ann", "", "ann", "", true},
+		{"ann@example.com", "", "", "ann@example.com", true},
+		{"5b10ac8d", "5b10ac8d", "", "", true},
+		{"", "", "", "", false},
+	}
+
+	for _, c := range cases {
+		if same := sameUser(c.user, c.accountID, c.name, c.mail); same != c.same {
+			t.Errorf("Wrong match of %s with %s/%s/%s, got: %t, want: %t.", c.user, c.accountID, c.name, c.mail, same, c.same)
+		}
+	}
+}
+
+func TestUsersTimeEntryAccountID(t *testing.T) {
+	config := DefaultConfig()
+	config.AccountID = "5b10ac8d"
+
+	entry := timeEntry1
+	entry.Employee = config.Username
+	if usersTimeEntry(entry, config) {
+		t.Errorf("With an account the name should not be enough")
+	}
+
+	entry.Employee = ""
+	entry.AuthorAccountID = "5b10ac8d"
+	if !usersTimeEntry(entry, config) {
+		t.Errorf("The account should match")
+	}
+}
+
+func TestUsersIssueAccountID(t *testing.T) {
+	config := DefaultConfig()
+	config.Username = "ann"
+
+	if usersIssue(SprintIssue{assignee: "anna@example.com"}, config) {
+		t.Errorf("ann should not match anna")
+	}
+	if !usersIssue(SprintIssue{assignee: "ann@example.com"}, config) {
+		t.Errorf("ann should match ann@example.com")
+	}
+
+	config.AccountID = "5b10ac8d"
+	if !usersIssue(SprintIssue{assignee: "hidden", assigneeID: "5b10ac8d"}, config) {
+		t.Errorf("The account should match")
+	}
+}
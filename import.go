@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"gopkg.in/yaml.v2"
+)
+
+// importColumns are the columns of an import CSV, in order
+var importColumns = []string{"issue", "date", "start", "duration", "comment"}
+
+// issueGetter is the part of the Jira client we need to check issues
+type issueGetter interface {
+	Get(issueID string, options *jira.GetQueryOptions) (*jira.Issue, *jira.Response, error)
+}
+
+// An ImportRow is one worklog in an import file
+type ImportRow struct {
+	Issue    string `yaml:"issue"`
+	Date     string `yaml:"date"`
+	Start    string `yaml:"start"`
+	Duration string `yaml:"duration"`
+	Comment  string `yaml:"comment"`
+	// Row is the record in a CSV, counting the header, or the entry in a YAML
+	Row int `yaml:"-"`
+}
+
+// An ImportWorklog is a validated row, ready to be logged
+type ImportWorklog struct {
+	ImportRow
+	Hours   int
+	Minutes int
+	Started time.Time
+}
+
+// key tells worklogs apart when resuming an import. It leaves out the
+// row number, so a row that was already logged is skipped even if rows
+// are added, removed or fixed around it. It has the resolved start, not
+// the date as written, so yesterday imported again a day later is new.
+func (w ImportWorklog) key() string {
+	return fmt.Sprintf("%s|%s|%d|%s", w.Issue, w.Started.Format(time.RFC3339), w.Hours*60+w.Minutes, w.Comment)
+}
+
+// ReadImportFile reads the rows of a CSV or YAML import file
+func ReadImportFile(file string) ([]ImportRow, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		return readImportYAML(data)
+	}
+	return readImportCSV(bytes.NewReader(data))
+}
+
+// readImportCSV reads rows of issue, date, start, duration and
+// comment. The header row, if there is one, is skipped.
+func readImportCSV(r io.Reader) (rows []ImportRow, err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if row == 1 && strings.EqualFold(record[0], importColumns[0]) {
+			continue
+		}
+		if len(record) < 4 || len(record) > len(importColumns) {
+			return nil, fmt.Errorf("row %d: want the columns %s", row, strings.Join(importColumns, ", "))
+		}
+
+		record = append(record, make([]string, len(importColumns)-len(record))...)
+		rows = append(rows, ImportRow{
+			Issue:    strings.TrimSpace(record[0]),
+			Date:     strings.TrimSpace(record[1]),
+			Start:    strings.TrimSpace(record[2]),
+			Duration: strings.TrimSpace(record[3]),
+			Comment:  record[4],
+			Row:      row,
+		})
+	}
+}
+
+// readImportYAML reads a list of rows with the same fields as the CSV
+func readImportYAML(data []byte) (rows []ImportRow, err error) {
+	err = yaml.Unmarshal(data, &rows)
+	for i := range rows {
+		rows[i].Row = i + 1
+	}
+	return rows, err
+}
+
+// ValidateImport checks every row before anything is logged, so a
+// typo on the last row does not leave the import half done. Each
+// issue is only looked up once.
+func ValidateImport(rows []ImportRow, getter issueGetter, config ChronosConfig, now time.Time, force bool) (worklogs []ImportWorklog, errs []error) {
+	checked := make(map[string]error)
+
+	for _, row := range rows {
+		fail := func(err error) {
+			errs = append(errs, fmt.Errorf("row %d: %s", row.Row, err))
+		}
+
+		if row.Issue == "" || row.Date == "" || row.Duration == "" {
+			fail(fmt.Errorf("need an issue, a date and a duration"))
+			continue
+		}
+
+		spent, err := ParseTimeSpent(row.Duration, config.DayLength())
+		if err != nil {
+			fail(err)
+			continue
+		}
+
+		started, err := WorkStarted(row.Date, row.Start, spent, now, force)
+		if err != nil {
+			fail(err)
+			continue
+		}
+
+		if _, ok := checked[row.Issue]; !ok {
+			_, resp, err := getter.Get(row.Issue, &jira.GetQueryOptions{Fields: "summary"})
+			if err != nil {
+				err = classifyError(fmt.Sprintf("issue %s", row.Issue), resp, err)
+			}
+			checked[row.Issue] = err
+		}
+		if err := checked[row.Issue]; err != nil {
+			fail(err)
+			continue
+		}
+
+		worklog := ImportWorklog{ImportRow: row, Started: started}
+		worklog.Hours, worklog.Minutes = splitDuration(spent.On(started))
+		worklogs = append(worklogs, worklog)
+	}
+	return
+}
+
+// alreadyLogged tells which worklogs the progress covers. Identical
+// rows share a key, so the first ones are logged as many times as the
+// key was, and the rest are not.
+func alreadyLogged(worklogs []ImportWorklog, done map[string]int) []bool {
+	seen := make(map[string]int)
+	logged := make([]bool, len(worklogs))
+	for i, worklog := range worklogs {
+		seen[worklog.key()]++
+		logged[i] = seen[worklog.key()] <= done[worklog.key()]
+	}
+	return logged
+}
+
+// PreviewImport prints the worklogs about to be logged
+func PreviewImport(worklogs []ImportWorklog, done map[string]int) (out bytes.Buffer) {
+	logged := alreadyLogged(worklogs, done)
+
+	var hours, minutes int
+	for i, worklog := range worklogs {
+		status := ""
+		if logged[i] {
+			status = "(already logged)"
+		} else {
+			hours += worklog.Hours
+			minutes += worklog.Minutes
+		}
+		out.WriteString(fmt.Sprintf("%4d  %-10s %s %3dh %2dm  %s %s\n", worklog.Row, worklog.Issue,
+			worklog.Started.Format("2006-01-02 15:04"), worklog.Hours, worklog.Minutes, worklog.Comment, status))
+	}
+	out.WriteString(fmt.Sprintf("\tTotal: %dh %dm\n", hours+minutes/60, minutes%60))
+	return
+}
+
+// ImportProgressFile is where the rows already logged by imports are
+// kept, so an import that failed half way can be resumed
+func ImportProgressFile() (string, error) {
+	return chronosFile("imports.json")
+}
+
+// ImportProgress is the rows already logged, per import file. A row
+// logged twice, e.g, the same meeting on two lines, is listed twice.
+type ImportProgress map[string][]string
+
+// LoadImportProgress reads how many times each row of the import
+// file was logged
+func LoadImportProgress(progressFile, importFile string) (map[string]int, error) {
+	var progress ImportProgress
+	err := readJSONFile(progressFile, &progress)
+
+	done := make(map[string]int)
+	for _, key := range progress[importFile] {
+		done[key]++
+	}
+	return done, err
+}
+
+// saveImportProgress writes the rows already logged from the import
+// file. Finished imports are kept too, so importing the same file
+// again does not log everything twice.
+func saveImportProgress(progressFile, importFile string, done map[string]int) error {
+	var progress ImportProgress
+	err := readJSONFile(progressFile, &progress)
+	if err != nil {
+		return err
+	}
+	if progress == nil {
+		progress = make(ImportProgress)
+	}
+
+	delete(progress, importFile)
+	for key, count := range done {
+		for i := 0; i < count; i++ {
+			progress[importFile] = append(progress[importFile], key)
+		}
+	}
+	return writeJSONFile(progressFile, progress)
+}
+
+// RunImport logs the worklogs not already logged, one by one, and
+// reports how each went. The progress is saved after every row, so
+// running the import again only logs the rows that failed.
+func RunImport(worklogs []ImportWorklog, progressFile, importFile string, logWork worklogFunc) (out bytes.Buffer, failed int, err error) {
+	done, err := LoadImportProgress(progressFile, importFile)
+	if err != nil {
+		return out, 0, err
+	}
+	logged := alreadyLogged(worklogs, done)
+
+	for i, worklog := range worklogs {
+		if logged[i] {
+			out.WriteString(fmt.Sprintf("row %d: skipped, already logged to %s\n", worklog.Row, worklog.Issue))
+			continue
+		}
+
+		err := logWork(worklog.Issue, worklog.Hours, worklog.Minutes, worklog.Comment, worklog.Started)
+		if err != nil {
+			failed++
+			out.WriteString(fmt.Sprintf("row %d: failed, %s\n", worklog.Row, err))
+			continue
+		}
+
+		done[worklog.key()]++
+		out.WriteString(fmt.Sprintf("row %d: logged %dh %dm to %s\n", worklog.Row, worklog.Hours, worklog.Minutes, worklog.Issue))
+		if err := saveImportProgress(progressFile, importFile, done); err != nil {
+			return out, failed, err
+		}
+	}
+
+	return out, failed, nil
+}
+
+// PendingImport counts the worklogs not already logged
+func PendingImport(worklogs []ImportWorklog, done map[string]int) (pending int) {
+	for _, logged := range alreadyLogged(worklogs, done) {
+		if !logged {
+			pending++
+		}
+	}
+	return
+}
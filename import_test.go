@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+type fakeIssueGetter struct {
+	missing map[string]bool
+	calls   int
+}
+
+func (f *fakeIssueGetter) Get(issueID string, options *jira.GetQueryOptions) (*jira.Issue, *jira.Response, error) {
+	f.calls++
+	if f.missing[issueID] {
+		return nil, nil, fmt.Errorf("issue does not exist")
+	}
+	return &jira.Issue{Key: issueID}, nil, nil
+}
+
+// 2026-10-14 is a Wednesday
+var importNow = time.Date(2026, 10, 14, 15, 0, 0, 0, time.UTC)
+
+func TestReadImportCSV(t *testing.T) {
+	rows, err := ReadImportFile("testdata/import.csv")
+	if err != nil {
+		t.Fatalf("Unable to read import file %s", err)
+	}
+
+	want := []ImportRow{
+		{Issue: "AA-1234", Date: "2026-10-12", Start: "09:00", Duration: "1h30m", Comment: "Workshop", Row: 2},
+		{Issue: "AA-1235", Date: "2026-10-12", Duration: "2h", Comment: "Notes, and follow up", Row: 3},
+		{Issue: "AA-1234", Date: "2026-10-13", Duration: "09:00-10:30", Row: 4},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Wrong number of rows, got: %d, want: %d.", len(rows), len(want))
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("Wrong row, got: %v, want: %v.", rows[i], want[i])
+		}
+	}
+}
+
+func TestReadImportYAML(t *testing.T) {
+	rows, err := ReadImportFile("testdata/import.yaml")
+	if err != nil {
+		t.Fatalf("Unable to read import file %s", err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("Wrong number of rows, got: %d, want: %d.", len(rows), 2)
+	}
+	if rows[0].Start != "09:00" || rows[1].Issue != "AA-1235" || rows[1].Row != 2 {
+		t.Errorf("Wrong rows, got: %v.", rows)
+	}
+}
+
+func TestValidateImport(t *testing.T) {
+	rows, _ := ReadImportFile("testdata/import.csv")
+	getter := &fakeIssueGetter{}
+
+	worklogs, errs := ValidateImport(rows, getter, DefaultConfig(), importNow, false)
+	if len(errs) != 0 {
+		t.Fatalf("Valid rows failed: %v", errs)
+	}
+	if getter.calls != 2 {
+		t.Errorf("Each issue should be checked once, got: %d, want: %d.", getter.calls, 2)
+	}
+
+	started := []time.Time{
+		time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 13, 9, 0, 0, 0, time.UTC),
+	}
+	for i, worklog := range worklogs {
+		if !worklog.Started.Equal(started[i]) {
+			t.Errorf("Wrong start of row %d, got: %s, want: %s.", worklog.Row, worklog.Started, started[i])
+		}
+	}
+	if worklogs[0].Hours != 1 || worklogs[0].Minutes != 30 {
+		t.Errorf("Wrong time, got: %dh %dm, want: 1h 30m.", worklogs[0].Hours, worklogs[0].Minutes)
+	}
+}
+
+func TestValidateImportReportsAllRows(t *testing.T) {
+	rows := []ImportRow{
+		{Issue: "AA-1234", Date: "2026-10-12", Duration: "1x", Row: 1},
+		{Issue: "AA-9999", Date: "2026-10-12", Duration: "1h", Row: 2},
+		{Issue: "AA-1234", Date: "2026-10-20", Duration: "1h", Row: 3},
+		{Issue: "AA-1234", Duration: "1h", Row: 4},
+		{Issue: "AA-1234", Date: "2026-10-12", Duration: "1h", Row: 5},
+	}
+	getter := &fakeIssueGetter{missing: map[string]bool{"AA-9999": true}}
+
+	worklogs, errs := ValidateImport(rows, getter, DefaultConfig(), importNow, false)
+	if len(errs) != 4 {
+		t.Errorf("Wrong number of errors, got: %d, want: %d.", len(errs), 4)
+	}
+	if len(worklogs) != 1 || worklogs[0].Row != 5 {
+		t.Errorf("Only the last row is valid, got: %v.", worklogs)
+	}
+}
+
+func TestRunImportResumes(t *testing.T) {
+	progressFile := filepath.Join(os.TempDir(), "chronos-import", "imports.json")
+	defer os.RemoveAll(filepath.Dir(progressFile))
+
+	rows, _ := ReadImportFile("testdata/import.csv")
+	worklogs, _ := ValidateImport(rows, &fakeIssueGetter{}, DefaultConfig(), importNow, false)
+
+	var logged []string
+	logWork := func(issue string, hours, minutes int, comment string, started time.Time) error {
+		if issue == "AA-1235" && len(logged) < 2 {
+			return fmt.Errorf("rate limited")
+		}
+		logged = append(logged, issue)
+		return nil
+	}
+
+	_, failed, err := RunImport(worklogs, progressFile, "import.csv", logWork)
+	if err != nil || failed != 1 {
+		t.Fatalf("One row should fail, got: %d %v", failed, err)
+	}
+
+	done, _ := LoadImportProgress(progressFile, "import.csv")
+	if len(done) != 2 {
+		t.Errorf("Wrong progress, got: %d, want: %d.", len(done), 2)
+	}
+
+	_, failed, err = RunImport(worklogs, progressFile, "import.csv", logWork)
+	if err != nil || failed != 0 {
+		t.Fatalf("The resumed import should succeed, got: %d %v", failed, err)
+	}
+	if len(logged) != 3 || logged[2] != "AA-1235" {
+		t.Errorf("Only the failed row should be logged again, got: %v.", logged)
+	}
+
+	_, failed, err = RunImport(worklogs, progressFile, "import.csv", logWork)
+	if err != nil || failed != 0 || len(logged) != 3 {
+		t.Errorf("A finished import should not be logged again, got: %v.", logged)
+	}
+}
+
+func TestRunImportMovedAndIdenticalRows(t *testing.T) {
+	progressFile := filepath.Join(os.TempDir(), "chronos-import-moved", "imports.json")
+	defer os.RemoveAll(filepath.Dir(progressFile))
+
+	standup := ImportRow{Issue: "AA-1234", Date: "2026-10-12", Duration: "15m", Comment: "Standup", Row: 2}
+	rows := []ImportRow{standup, standup}
+	worklogs, _ := ValidateImport(rows, &fakeIssueGetter{}, DefaultConfig(), importNow, false)
+
+	var logged []string
+	logWork := func(issue string, hours, minutes int, comment string, started time.Time) error {
+		logged = append(logged, issue)
+		return nil
+	}
+
+	_, _, err := RunImport(worklogs[:1], progressFile, "import.csv", logWork)
+	if err != nil || len(logged) != 1 {
+		t.Fatalf("The first row should be logged, got: %v %v", logged, err)
+	}
+
+	// A new first row moves the others down
+	added := ImportRow{Issue: "AA-1235", Date: "2026-10-12", Duration: "1h", Row: 2}
+	rows = []ImportRow{added, standup, standup}
+	worklogs, _ = ValidateImport(rows, &fakeIssueGetter{}, DefaultConfig(), importNow, false)
+
+	if pending := PendingImport(worklogs, map[string]int{worklogs[1].key(): 1}); pending != 2 {
+		t.Errorf("Wrong number of pending rows, got: %d, want: %d.", pending, 2)
+	}
+
+	_, _, err = RunImport(worklogs, progressFile, "import.csv", logWork)
+	if err != nil || len(logged) != 3 || logged[1] != "AA-1235" || logged[2] != "AA-1234" {
+		t.Errorf("The new row and the second standup should be logged, got: %v %v", logged, err)
+	}
+
+	done, _ := LoadImportProgress(progressFile, "import.csv")
+	if done[worklogs[1].key()] != 2 || done[worklogs[0].key()] != 1 {
+		t.Errorf("Wrong progress, got: %v.", done)
+	}
+}
+
+func TestImportKeyRelativeDate(t *testing.T) {
+	rows := []ImportRow{{Issue: "AA-1234", Date: "yesterday", Duration: "1h", Row: 2}}
+
+	today, _ := ValidateImport(rows, &fakeIssueGetter{}, DefaultConfig(), importNow, false)
+	tomorrow, _ := ValidateImport(rows, &fakeIssueGetter{}, DefaultConfig(), importNow.AddDate(0, 0, 1), false)
+
+	if today[0].key() == tomorrow[0].key() {
+		t.Errorf("Yesterday imported on another day is another worklog, got the key %s twice.", today[0].key())
+	}
+	if PendingImport(tomorrow, map[string]int{today[0].key(): 1}) != 1 {
+		t.Errorf("The row should not count as already logged")
+	}
+}
+
+func TestPreviewImport(t *testing.T) {
+	rows, _ := ReadImportFile("testdata/import.csv")
+	worklogs, _ := ValidateImport(rows, &fakeIssueGetter{}, DefaultConfig(), importNow, false)
+
+	preview := PreviewImport(worklogs, map[string]int{worklogs[1].key(): 1})
+	want := "   2  AA-1234    2026-10-12 09:00   1h 30m  Workshop \n" +
+		"   3  AA-1235    2026-10-12 09:00   2h  0m  Notes, and follow up (already logged)\n" +
+		"   4  AA-1234    2026-10-13 09:00   1h 30m   \n" +
+		"\tTotal: 3h 0m\n"
+	if preview.String() != want {
+		t.Errorf("Wrong preview, got:\n%s\nwant:\n%s", preview.String(), want)
+	}
+}
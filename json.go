@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// JSONReport is the top level of the --format json output.
+// The schema is documented in README.md, keep them in sync.
+type JSONReport struct {
+	Weeks []JSONWeek `json:"weeks"`
+	Total float64    `json:"total"`
+}
+
+// JSONWeek is one ISO week of the report with its total
+type JSONWeek struct {
+	Year  int        `json:"year"`
+	Week  int        `json:"week"`
+	Total float64    `json:"total"`
+	Dates []JSONDate `json:"dates"`
+}
+
+// JSONDate is one day of the report with its total
+type JSONDate struct {
+	Date   string      `json:"date"`
+	Total  float64     `json:"total"`
+	Issues []JSONIssue `json:"issues"`
+}
+
+// JSONIssue is the time spent on one issue during a day
+type JSONIssue struct {
+	Issue    string        `json:"issue"`
+	Summary  string        `json:"summary"`
+	Total    float64       `json:"total"`
+	Worklogs []JSONWorklog `json:"worklogs"`
+}
+
+// JSONWorklog is a single worklog
+type JSONWorklog struct {
+	ID      string  `json:"id,omitempty"`
+	Hours   float64 `json:"hours"`
+	Comment string  `json:"comment"`
+	Started string  `json:"started,omitempty"`
+}
+
+// jsonHours rounds hours the same way as the text report
+func jsonHours(hours float32) float64 {
+	return math.Round(float64(hours)*100) / 100
+}
+
+// BuildJSONReport runs the same state machine as PrettyPrint,
+// but collects the report in structs instead of text
+func BuildJSONReport(commands []Command) (report JSONReport) {
+	report.Weeks = []JSONWeek{}
+
+	var reportTotal float32 = 0.0
+	var weekTotal float32 = 0.0
+	var dateTotal float32 = 0.0
+	var issueTotal float32 = 0.0
+
+	var week *JSONWeek
+	var date *JSONDate
+	var issue *JSONIssue
+
+	for _, command := range commands {
+		switch cmd := command.(type) {
+
+		case clearWeek:
+			weekTotal = 0.0
+		case clearDate:
+			dateTotal = 0.0
+		case clearIssue:
+			issueTotal = 0.0
+
+		case newWeek:
+			report.Weeks = append(report.Weeks, JSONWeek{Year: cmd.week.Year, Week: cmd.week.Week, Dates: []JSONDate{}})
+			week = &report.Weeks[len(report.Weeks)-1]
+
+		case newDate:
+			week.Dates = append(week.Dates, JSONDate{Date: cmd.date, Issues: []JSONIssue{}})
+			date = &week.Dates[len(week.Dates)-1]
+
+		case newIssue:
+			date.Issues = append(date.Issues, JSONIssue{Issue: cmd.issue, Summary: cmd.summary, Worklogs: []JSONWorklog{}})
+			issue = &date.Issues[len(date.Issues)-1]
+
+		case summaryDate:
+			if date != nil {
+				date.Total = jsonHours(dateTotal)
+			}
+
+		case summaryWeek:
+			if week != nil {
+				week.Total = jsonHours(weekTotal)
+			}
+
+		case noteHours:
+			reportTotal += cmd.hours
+			weekTotal += cmd.hours
+			dateTotal += cmd.hours
+			issueTotal += cmd.hours
+
+			worklog := JSONWorklog{ID: cmd.worklogID, Hours: jsonHours(cmd.hours), Comment: cmd.comment}
+			if !cmd.started.IsZero() {
+				worklog.Started = cmd.started.Format(time.RFC3339)
+			}
+			issue.Worklogs = append(issue.Worklogs, worklog)
+			issue.Total = jsonHours(issueTotal)
+		}
+	}
+
+	report.Total = jsonHours(reportTotal)
+	return
+}
+
+// PrettyPrintJSON converts commands to an indented JSON document
+func PrettyPrintJSON(commands []Command) (out bytes.Buffer) {
+	data, err := json.MarshalIndent(BuildJSONReport(commands), "", "  ")
+	if err != nil {
+		// Only plain structs are marshalled, so this can not happen
+		panic(err)
+	}
+	out.Write(data)
+	out.WriteString("\n")
+	return
+}
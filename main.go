@@ -4,6 +4,10 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/andygrunwald/go-jira"
 )
@@ -18,13 +22,411 @@ var (
 	issue          = flag.String("issue", "", "issue to query or manipulate")
 	hours          = flag.Int("hours", 0, "hours to log time")
 	minutes        = flag.Int("minutes", 0, "minutes to log time")
+	timeSpent      = flag.String("time", "", "time to log, e.g, 1h30m, 1.5h, 90m, 1d or 09:00-10:30")
 	comment        = flag.String("comment", "", "worklog comment")
+	workDate       = flag.String("date", "", "day the work was done, e.g, 2026-09-01, yesterday, mon or -2d")
+	workStart      = flag.String("start", "", "time of the day the work started, e.g, 09:00")
+	force          = flag.Bool("force", false, "log work that starts in the future, or delete a worklog without asking")
+	dryRun         = flag.Bool("dry-run", false, "only check and preview the worklogs to import")
 	brief          = flag.Bool("brief", false, "print log with fewer details")
 	sprint         = flag.Bool("sprint", false, "show your issues in the active sprint(s)")
+	refresh        = flag.Bool("refresh", false, "ignore the local cache and fetch all worklogs again")
+	format         = flag.String("format", "text", "output format of the report: text, json, csv, markdown or html")
+	columns        = flag.String("columns", "", "comma separated csv columns, e.g, date,issue,hours")
+	aggregate      = flag.Bool("aggregate", false, "one csv row per day and issue instead of per worklog")
+	noLegend       = flag.Bool("no-legend", false, "do not list the issues at the end of the report")
+	shortLines     = flag.Bool("short-lines", false, "only show issue summaries in the legend")
+	comments       = flag.Bool("comments", false, "show worklog comments in the report")
+	offline        = flag.Bool("offline", false, "use the local cache and queue --logwork without contacting JIRA")
+	onlyGaps       = flag.Bool("gaps", false, "only list the work days and hours still to be logged")
+	from           = flag.String("from", "", "first date of the report, e.g, 2026-09-01")
+	to             = flag.String("to", "", "last date of the report, e.g, 2026-09-30")
+	period         = flag.String("period", "", "period of the report: "+strings.Join(Periods, ", "))
+	groupBy        = flag.String("group-by", "", "group the report by "+strings.Join(GroupBys, ", "))
+	breakdown      = flag.Bool("breakdown", false, "show the hours per project and epic after the report")
+	users          = flag.String("users", "", "comma separated users for a team report, e.g, alice,bob")
+	team           = flag.String("team", "", "team in the config for a team report")
+	jiraGroup      = flag.String("jira-group", "", "JIRA group for a team report")
 )
 
-func main() {
+// exitWithError renders an error with a hint on how
+// to fix it and exits with the code for its kind
+func exitWithError(err error) {
+	log.Print(err)
+	fmt.Fprintln(os.Stderr, ErrorHint(err))
+	os.Exit(ExitCode(err))
+}
+
+func printSprintIssues(sprintIssues []SprintIssue) {
+	for _, issue := range sprintIssues {
+		fmt.Printf("%s: %s [%s]\n", issue.issue, issue.summary, issue.assignee)
+	}
+}
+
+func printTimeEntries(timeEntries []TimeEntry, config ChronosConfig) {
+	if *format == "csv" && !*onlyGaps {
+		PrintCSV(timeEntries, config)
+		return
+	}
+
+	if config.IsTeam() {
+		output := PrettyPrintTeam(timeEntries, config)
+		if *brief {
+			output = PrettyPrintTeamBrief(timeEntries, config)
+		}
+		fmt.Print(output.String())
+		if config.Breakdown {
+			output = PrettyPrintBreakdown(timeEntries, config)
+			fmt.Print(output.String())
+		}
+		return
+	}
+
+	calendar, err := NewWorkCalendar(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	gaps := FindGaps(timeEntries, calendar, config.ReportRange().From)
+
+	if *onlyGaps {
+		if !calendar.Enabled() {
+			log.Fatalf("Unable to find gaps, need hoursperweek or a schedule in the config")
+		}
+		output := PrettyPrintGaps(gaps)
+		fmt.Print(output.String())
+		return
+	}
+
+	// Only the text report has placeholders for the gaps
+	if *format != "text" {
+		gaps = nil
+	}
+
+	renderer, err := NewRenderer(*format, *brief, config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	commands := BuildGroupedCommandsWithGaps(timeEntries, gaps, config.GroupBy)
+	output := renderer.Render(commands)
+	fmt.Print(output.String())
+
+	if config.Breakdown && *format == "text" {
+		output = PrettyPrintBreakdown(timeEntries, config)
+		fmt.Print(output.String())
+	}
+}
+
+// workLog is the work --logwork is about to log
+type workLog struct {
+	hours   int
+	minutes int
+	// started is zero when JIRA should use the time the work is logged
+	started time.Time
+}
+
+// teamReportError tells why the flags do not fit a team report. It has
+// its own text layout, and the other formats would mix everyone's
+// worklogs without telling whose they are, except for the csv author.
+// The grouping is checked in the config, since it can be set there too.
+func teamReportError(config ChronosConfig) error {
+	switch {
+	case *format != "text" && *format != "csv":
+		return fmt.Errorf("team reports are printed as text or csv, not %s", *format)
+	case *onlyGaps:
+		return fmt.Errorf("--gaps only works for your own report, not for a team")
+	case config.GroupBy != "" && config.GroupBy != "week":
+		return fmt.Errorf("grouping by %s only works for your own report, not for a team", config.GroupBy)
+	case *comments || *shortLines || *noLegend:
+		return fmt.Errorf("--comments, --short-lines and --no-legend only work for your own report, not for a team")
+	}
+	return nil
+}
+
+// timeFlags reads the time given either with --time,
+// or the old way with --hours and --minutes
+func timeFlags(config ChronosConfig) (TimeSpent, error) {
+	text := *timeSpent
+	if text == "" {
+		text = fmt.Sprintf("%dh%dm", *hours, *minutes)
+	} else if *hours != 0 || *minutes != 0 {
+		return TimeSpent{}, fmt.Errorf("use either --time or --hours and --minutes")
+	}
+	return ParseTimeSpent(text, config.DayLength())
+}
+
+// workToLog reads and validates the work to log before
+// anything is sent to JIRA. --date and --start tell when
+// the work started.
+func workToLog(config ChronosConfig) (work workLog, err error) {
+	if *issue == "" {
+		return work, fmt.Errorf("need --issue")
+	}
+
+	spent, err := timeFlags(config)
+	if err != nil {
+		return work, err
+	}
+
+	work.started, err = WorkStarted(*workDate, *workStart, spent, time.Now().In(config.Location()), *force)
+	work.hours, work.minutes = splitDuration(spent.On(work.started))
+	return work, err
+}
+
+// worklogChange reads and validates what chronos worklog edit
+// changes. Only the time, comment and start that are given change.
+// current is when the worklog started, a new start keeps its day.
+func worklogChange(config ChronosConfig, current time.Time) (change workLog, err error) {
+	var spent TimeSpent
+	if *timeSpent != "" || *hours != 0 || *minutes != 0 {
+		spent, err = timeFlags(config)
+		if err != nil {
+			return change, err
+		}
+	}
+
+	change.started, err = EditStarted(*workDate, *workStart, spent, current, time.Now().In(config.Location()), *force)
+	if err != nil {
+		return change, err
+	}
+	change.hours, change.minutes = splitDuration(spent.On(change.started))
+
+	if change == (workLog{}) && *comment == "" {
+		return change, fmt.Errorf("nothing to change, use --time, --comment, --date or --start")
+	}
+	return change, nil
+}
+
+// runWorklog runs the worklog list, edit and delete commands
+func runWorklog(client *jira.Client, config ChronosConfig, args []string, change workLog) {
+	if args[0] == "list" {
+		timeEntries, err := ListWorklogs(client, config, *issue)
+		if err != nil {
+			exitWithError(err)
+		}
+		out := PrettyPrintWorklogs(timeEntries)
+		fmt.Print(out.String())
+		return
+	}
+
+	worklogID := args[1]
+	worklogIssue := *issue
+
+	// A new start without --date needs the day the worklog is on
+	keepDay := args[0] == "edit" && *workDate == "" && !change.started.IsZero()
+	if worklogIssue == "" || keepDay {
+		foundIssue, started, err := FindWorklog(client, config, worklogID)
+		if err != nil {
+			exitWithError(err)
+		}
+		if worklogIssue == "" {
+			worklogIssue = foundIssue
+		}
+		if keepDay {
+			change, err = worklogChange(config, started)
+			if err != nil {
+				log.Fatalf("Unable to edit worklog, %s", err)
+			}
+		}
+	}
+
+	switch args[0] {
+	case "edit":
+		err := editWorklogInJIRA(client, worklogIssue, worklogID, change.hours, change.minutes, *comment, change.started)
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Printf("Successfully edited worklog %s\n", worklogID)
+
+	case "delete":
+		if !*force && !ConfirmDelete(os.Stdin, os.Stdout, worklogIssue, worklogID) {
+			fmt.Println("Nothing deleted")
+			return
+		}
+		err := deleteWorklogInJIRA(client, worklogIssue, worklogID)
+		if err != nil {
+			exitWithError(err)
+		}
+		fmt.Printf("Successfully deleted worklog %s\n", worklogID)
+	}
+}
+
+// parseCommand reads the flags and the command, e.g, start AA-1234.
+// Flags may come both before and after the command and its arguments.
+func parseCommand() (command string, args []string) {
 	flag.Parse()
+	for rest := flag.Args(); len(rest) > 0; rest = flag.Args() {
+		args = append(args, rest[0])
+		flag.CommandLine.Parse(rest[1:])
+	}
+
+	if len(args) == 0 {
+		return "", nil
+	}
+	return args[0], args[1:]
+}
+
+// runTimer runs the start, stop and status commands
+func runTimer(command string, args []string, config ChronosConfig, logWork worklogFunc) {
+	timerFile, err := TimerFile()
+	if err != nil {
+		log.Fatal(err)
+	}
+	now := time.Now().In(config.Location())
+
+	switch command {
+	case "status":
+		timer, err := LoadTimer(timerFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(TimerStatus(timer, now))
+
+	case "start":
+		if len(args) != 1 {
+			log.Fatalf("Unable to start a timer, use chronos start ISSUE")
+		}
+		previous, spent, err := StartTimer(timerFile, args[0], *comment, now, config.RoundTo(), logWork)
+		if err != nil {
+			exitWithError(err)
+		}
+		if previous != nil {
+			printStoppedTimer(previous, spent)
+		}
+		fmt.Printf("Started a timer on %s at %s\n", args[0], now.Format("15:04"))
+
+	case "stop":
+		timer, spent, err := StopTimer(timerFile, *comment, now, config.RoundTo(), logWork)
+		if err != nil {
+			exitWithError(err)
+		}
+		printStoppedTimer(timer, spent)
+	}
+}
+
+// runImport checks all worklogs in an import file, previews them
+// and logs them in JIRA
+func runImport(client *jira.Client, config ChronosConfig, importFile string) {
+	importFile, err := filepath.Abs(importFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rows, err := ReadImportFile(importFile)
+	if err != nil {
+		log.Fatalf("Unable to read %s, %s", importFile, err)
+	}
+
+	worklogs, errs := ValidateImport(rows, client.Issue, config, time.Now().In(config.Location()), *force)
+	if len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		log.Fatalf("Nothing imported, fix the %d rows above first", len(errs))
+	}
+
+	progressFile, err := ImportProgressFile()
+	if err != nil {
+		log.Fatal(err)
+	}
+	done, err := LoadImportProgress(progressFile, importFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	preview := PreviewImport(worklogs, done)
+	fmt.Print(preview.String())
+	if PendingImport(worklogs, done) == 0 {
+		fmt.Printf("Nothing to import, all rows of %s were already logged\n", importFile)
+		return
+	}
+	if *dryRun {
+		return
+	}
+	fmt.Println()
+
+	out, failed, err := RunImport(worklogs, progressFile, importFile, func(issue string, hours, minutes int, comment string, started time.Time) error {
+		return logWorkInJIRA(client, config, issue, hours, minutes, comment, started)
+	})
+	fmt.Print(out.String())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if failed > 0 {
+		log.Fatalf("%d rows failed, run the import again to retry them", failed)
+	}
+}
+
+func printStoppedTimer(timer *Timer, spent time.Duration) {
+	hours, minutes := splitDuration(spent)
+	if spent == 0 {
+		fmt.Printf("Stopped the timer on %s, nothing to log\n", timer.Issue)
+		return
+	}
+	fmt.Printf("Stopped the timer on %s, logged %dh %dm\n", timer.Issue, hours, minutes)
+}
+
+// queueWorklog logs work in the offline queue
+func queueWorklog(issue string, hours, minutes int, comment string, started time.Time) error {
+	return QueueWorklog(PendingWorklog{
+		Issue:   issue,
+		Hours:   hours,
+		Minutes: minutes,
+		Comment: comment,
+		Started: started,
+	})
+}
+
+// runOffline answers from the local cache without contacting JIRA
+func runOffline(config ChronosConfig, command string, args []string, work workLog) {
+	if len(config.Users) > 0 || *jiraGroup != "" {
+		log.Fatalf("Team reports are not cached, they need a connection to JIRA")
+	}
+
+	if command != "" {
+		runTimer(command, args, config, queueWorklog)
+		return
+	}
+
+	if *logWork {
+		started := work.started
+		if started.IsZero() {
+			started = time.Now()
+		}
+		err := queueWorklog(*issue, work.hours, work.minutes, *comment, started)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Offline, queued %dh %dm to %s for the next online run\n", work.hours, work.minutes, *issue)
+		return
+	}
+
+	if *sprint {
+		sprintIssues, synced, err := OfflineSprintIssues()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Print(OfflineStamp(synced))
+		printSprintIssues(sprintIssues)
+		return
+	}
+
+	timeEntries, synced, err := OfflineTimeEntries(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Keep machine readable output clean
+	if *format == "text" {
+		fmt.Print(OfflineStamp(synced))
+	} else {
+		fmt.Fprint(os.Stderr, OfflineStamp(synced))
+	}
+	printTimeEntries(timeEntries, config)
+}
+
+func main() {
+	command, args := parseCommand()
 
 	if *generateConfig {
 		GenerateExampleConfigInHome()
@@ -43,6 +445,127 @@ func main() {
 		config = CommandlineConfig(*url, *mail, *username, *apikey)
 	}
 
+	if !ValidFormat(*format) {
+		log.Fatalf("Unknown format %s, use one of %s", *format, strings.Join(Formats, ", "))
+	}
+
+	if *columns != "" {
+		config.CSVColumns = strings.Split(*columns, ",")
+	}
+	if *aggregate {
+		config.CSVAggregate = true
+	}
+	if *noLegend {
+		config.NoLegend = true
+	}
+	if *shortLines {
+		config.ShortLines = true
+	}
+	if *comments {
+		config.Comments = true
+	}
+	if *breakdown {
+		config.Breakdown = true
+	}
+	if *groupBy != "" {
+		config.GroupBy = *groupBy
+	}
+	if !ValidGroupBy(config.GroupBy) {
+		log.Fatalf("Unknown grouping %s, use one of %s", config.GroupBy, strings.Join(GroupBys, ", "))
+	}
+	// JSON and CSV have a fixed layout for scripts
+	if config.GroupBy != "week" && (*format == "json" || *format == "csv") {
+		log.Fatalf("Unable to group %s output, --group-by only works for text, markdown and html", *format)
+	}
+	if err := ValidateCSVColumns(config.CSVColumns); err != nil {
+		log.Fatal(err)
+	}
+
+	config.Range = DateRange{From: *from, To: *to}
+	if *period != "" {
+		if *from != "" || *to != "" {
+			log.Fatalf("Use either --period or --from/--to, not both")
+		}
+		config.Range, err = PeriodRange(*period, time.Now().In(config.Location()))
+		if err != nil {
+			log.Fatalf("%s, use one of %s", err, strings.Join(Periods, ", "))
+		}
+	}
+	if err := config.ValidateRange(); err != nil {
+		log.Fatal(err)
+	}
+
+	if *users != "" {
+		config.Users = ParseUsers(*users)
+	}
+	if *team != "" {
+		members, ok := config.Teams[*team]
+		if !ok {
+			log.Fatalf("Unknown team %s, add it under teams in the config", *team)
+		}
+		config.Users = append(config.Users, members...)
+	}
+	config.Users = UniqueUsers(config.Users)
+	if config.IsTeam() || *jiraGroup != "" {
+		if err := teamReportError(config); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	switch command {
+	case "":
+	case "status":
+		// The timer is local, there is no need to contact JIRA
+		runTimer(command, args, config, nil)
+		return
+	case "start", "stop":
+		if *logWork {
+			log.Fatalf("Use either --logwork or chronos %s", command)
+		}
+	case "import":
+		if len(args) != 1 {
+			log.Fatalf("Unable to import, use chronos import FILE")
+		}
+		if *offline {
+			log.Fatalf("Unable to import offline, the issues are checked in JIRA")
+		}
+	case "worklog":
+		switch {
+		case len(args) == 1 && args[0] == "list":
+			if *issue == "" {
+				log.Fatalf("Unable to list worklogs, need --issue")
+			}
+		case len(args) == 2 && (args[0] == "edit" || args[0] == "delete"):
+		default:
+			log.Fatalf("Unknown worklog command, use chronos worklog list, edit ID or delete ID")
+		}
+		if *offline {
+			log.Fatalf("Unable to change worklogs offline")
+		}
+	default:
+		log.Fatalf("Unknown command %s, use start, stop, status, import or worklog", command)
+	}
+
+	var work workLog
+	if command == "worklog" && args[0] == "edit" {
+		// The day of the worklog is only known once Jira is asked
+		work, err = worklogChange(config, time.Time{})
+		if err != nil {
+			log.Fatalf("Unable to edit worklog, %s", err)
+		}
+	}
+	if *logWork {
+		work, err = workToLog(config)
+		if err != nil {
+			log.Fatalf("Unable to log work, %s", err)
+		}
+	}
+
+	if *offline {
+		runOffline(config, command, args, work)
+		return
+	}
+
 	tp := jira.BasicAuthTransport{
 		Username: config.Jira.Mail,
 		Password: config.Jira.APIKey,
@@ -54,44 +577,77 @@ func main() {
 		return
 	}
 
+	myselfFile, err := MyselfFile()
+	if err == nil {
+		config.Jira.AccountID, err = ResolveAccountID(client.User, config, myselfFile)
+	}
+	if err != nil {
+		log.Printf("[identity] Unable to find your account, matching on username %s", err)
+	}
+
+	if *jiraGroup != "" {
+		members, err := GroupMembers(client.Group, *jiraGroup)
+		if err != nil {
+			exitWithError(err)
+		}
+		config.Users = UniqueUsers(append(config.Users, members...))
+	}
+
+	err = PushQueuedWorklogs(client, config)
+	if err != nil {
+		log.Printf("[offline] Unable to push queued worklogs %s", err)
+	}
+
+	if command == "import" {
+		runImport(client, config, args[0])
+		return
+	}
+
+	if command == "worklog" {
+		runWorklog(client, config, args, work)
+		return
+	}
+
+	if command != "" {
+		runTimer(command, args, config, func(issue string, hours, minutes int, comment string, started time.Time) error {
+			return logWorkInJIRA(client, config, issue, hours, minutes, comment, started)
+		})
+		return
+	}
+
 	if *logWork {
-		if *issue != "" && (*hours > 0 || *minutes > 0) {
-			err := logWorkInJIRA(client, config, *issue, *hours, *minutes, *comment)
-			if err != nil {
-				log.Fatal(err)
-			} else {
-				fmt.Printf("Successfully logged %dh %dm to %s\n", *hours, *minutes, *issue)
-			}
-		} else {
-			log.Fatalf("Unable to log work, need --issue, --hours and/or --minutes")
+		err := logWorkInJIRA(client, config, *issue, work.hours, work.minutes, *comment, work.started)
+		if err != nil {
+			exitWithError(err)
 		}
+		fmt.Printf("Successfully logged %dh %dm to %s\n", work.hours, work.minutes, *issue)
 		return
 	}
 
 	if *sprint {
 		sprintIssues, err := UsersIssuesInOpenSprints(client, config)
 		if err != nil {
-			log.Fatal(err)
-			return
+			exitWithError(err)
 		}
 
-		for _, issue := range sprintIssues {
-			fmt.Printf("%s: %s [%s]\n", issue.issue, issue.summary, issue.assignee)
+		err = SaveSprintIssues(sprintIssues)
+		if err != nil {
+			log.Printf("[sprint] Unable to save sprint for offline use %s", err)
 		}
 
+		printSprintIssues(sprintIssues)
 		return
 	}
 
-	timeEntries, nil := ExtractTimeEntriesFromJira(client, config)
-	if err != nil {
-		log.Fatal(err)
-		return
+	timeEntries, err := SyncTimeEntries(client, config, *refresh)
+	if err != nil && errorKind(err) != PartialError {
+		exitWithError(err)
 	}
 
-	if *brief {
-		PrintBrief(timeEntries)
-	} else {
-		Print(timeEntries)
-	}
+	printTimeEntries(timeEntries, config)
 
+	// Partial results are still worth showing, but warn about them
+	if err != nil {
+		exitWithError(err)
+	}
 }
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// markdownRenderer prints one table per week, ready to be
+// pasted into a wiki page or a pull request description.
+// The last column is the worklog ID, for chronos worklog.
+type markdownRenderer struct {
+	baseURL string
+}
+
+// markdownEscape keeps text from breaking out of a table cell
+func markdownEscape(text string) string {
+	text = strings.Replace(text, "|", "\\|", -1)
+	return strings.Replace(text, "\n", " ", -1)
+}
+
+// markdownCell pads a cell, keeping empty cells to a single space
+func markdownCell(text string) string {
+	if text == "" {
+		return " "
+	}
+	return " " + text + " "
+}
+
+func (r markdownRenderer) issueLink(issue string) string {
+	url := issueURL(r.baseURL, issue)
+	if url == "" {
+		return issue
+	}
+	return fmt.Sprintf("[%s](%s)", issue, url)
+}
+
+func (r markdownRenderer) Render(commands []Command) (out bytes.Buffer) {
+	var sectionTotal float32 = 0.0
+	var weekTotal float32 = 0.0
+	var dateTotal float32 = 0.0
+	var issueHours float32 = 0.0
+
+	var week ISOWeek
+	var weekStarted bool = false
+	var date string = ""
+	var issue string = ""
+	var issueText string = ""
+	var worklogID string = ""
+
+	// The worklogs of a date, for a compact date
+	var dateWorklogs []string
+
+	// Only the first row of a date shows the date
+	var dateCell string = ""
+
+	for _, command := range commands {
+		switch cmd := command.(type) {
+
+		case clearSection:
+			sectionTotal = 0.0
+		case clearWeek:
+			weekTotal = 0.0
+		case clearDate:
+			dateTotal = 0.0
+			dateWorklogs = nil
+		case clearIssue:
+			issueHours = 0.0
+
+		case newWeek:
+			week = cmd.week
+			weekStarted = true
+			out.WriteString(fmt.Sprintf("## Week %s\n\n", week))
+			out.WriteString("| Date | Issue | Hours | Summary | Worklog |\n")
+			out.WriteString("|------|-------|------:|---------|---------|\n")
+
+		case newSection:
+			out.WriteString(fmt.Sprintf("## %s\n\n", markdownEscape(cmd.title)))
+			out.WriteString("| Date | Issue | Hours | Summary | Worklog |\n")
+			out.WriteString("|------|-------|------:|---------|---------|\n")
+
+		case summarySection:
+			out.WriteString(fmt.Sprintf("\n**Total: %.2f**\n\n", sectionTotal))
+
+		case printDateTotal:
+			out.WriteString(fmt.Sprintf("| %s | | %.2f | |%s|\n", cmd.date, dateTotal, markdownCell(strings.Join(dateWorklogs, ", "))))
+
+		case newDate:
+			date = cmd.date
+			dateCell = date
+
+		case newIssue:
+			issue = cmd.issue
+			issueText = cmd.summary
+
+		case summaryDate:
+			if date != "" {
+				out.WriteString(fmt.Sprintf("| | **Total** | **%.2f** | | |\n", dateTotal))
+			}
+
+		case summaryWeek:
+			if weekStarted {
+				out.WriteString(fmt.Sprintf("\n**Week total: %.2f**\n\n", weekTotal))
+			}
+
+		case noteHours:
+			sectionTotal += cmd.hours
+			weekTotal += cmd.hours
+			dateTotal += cmd.hours
+			issueHours = cmd.hours
+			worklogID = cmd.worklogID
+			if worklogID != "" {
+				dateWorklogs = append(dateWorklogs, worklogID)
+			}
+
+		case printNewIssue:
+			out.WriteString(fmt.Sprintf("|%s| %s | %.2f | %s |%s|\n", markdownCell(dateCell), r.issueLink(issue), issueHours, markdownEscape(issueText), markdownCell(worklogID)))
+			dateCell = ""
+
+		case printSameIssue:
+			out.WriteString(fmt.Sprintf("| | | %.2f | |%s|\n", issueHours, markdownCell(worklogID)))
+		}
+	}
+	return
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// A PendingWorklog is a --logwork request made while offline.
+// It is pushed to Jira on the next online run.
+type PendingWorklog struct {
+	Issue   string    `json:"issue"`
+	Hours   int       `json:"hours"`
+	Minutes int       `json:"minutes"`
+	Comment string    `json:"comment"`
+	Started time.Time `json:"started"`
+}
+
+// sprintSnapshot is the sprint view as it looked at the last online run
+type sprintSnapshot struct {
+	Synced time.Time           `json:"synced"`
+	Issues []cachedSprintIssue `json:"issues"`
+}
+
+type cachedSprintIssue struct {
+	Issue    string `json:"issue"`
+	Summary  string `json:"summary"`
+	Assignee string `json:"assignee"`
+}
+
+// QueueFile is where worklogs made while offline are kept
+func QueueFile() (string, error) {
+	return chronosFile("queue.json")
+}
+
+// SprintFile is where the last sprint view is kept
+func SprintFile() (string, error) {
+	return chronosFile("sprint.json")
+}
+
+// LoadQueue reads the pending worklogs
+func LoadQueue(queueFile string) (pending []PendingWorklog, err error) {
+	err = readJSONFile(queueFile, &pending)
+	return pending, err
+}
+
+// SaveQueue writes the pending worklogs
+func SaveQueue(queueFile string, pending []PendingWorklog) error {
+	return writeJSONFile(queueFile, pending)
+}
+
+// QueueWorklog stores a worklog to be pushed on the next online run
+func QueueWorklog(worklog PendingWorklog) error {
+	queueFile, err := QueueFile()
+	if err != nil {
+		return err
+	}
+
+	pending, err := LoadQueue(queueFile)
+	if err != nil {
+		return err
+	}
+
+	return SaveQueue(queueFile, append(pending, worklog))
+}
+
+// pushPendingWorklogs logs each pending worklog in Jira and returns the
+// ones that should be tried again. Worklogs on issues that do not exist
+// are dropped since retrying them will never succeed. The queue is saved
+// after each worklog that leaves it, and nothing more is logged if that
+// fails, so a worklog already in Jira is not logged again next time.
+func pushPendingWorklogs(client *jira.Client, config ChronosConfig, queueFile string, pending []PendingWorklog) (remaining []PendingWorklog, err error) {
+	for i, worklog := range pending {
+		err := logWorkInJIRA(client, config, worklog.Issue, worklog.Hours, worklog.Minutes, worklog.Comment, worklog.Started)
+		switch {
+		case err == nil:
+			log.Printf("[offline] Logged queued %dh %dm to %s", worklog.Hours, worklog.Minutes, worklog.Issue)
+		case errorKind(err) == NotFoundError:
+			log.Printf("[offline] Dropping queued worklog, %s", err)
+		default:
+			log.Printf("[offline] Keeping queued worklog, %s", err)
+			remaining = append(remaining, worklog)
+			continue
+		}
+
+		queue := append(append([]PendingWorklog{}, remaining...), pending[i+1:]...)
+		if err := SaveQueue(queueFile, queue); err != nil {
+			return queue, err
+		}
+	}
+	return remaining, nil
+}
+
+// PushQueuedWorklogs logs everything queued while offline in Jira
+func PushQueuedWorklogs(client *jira.Client, config ChronosConfig) error {
+	queueFile, err := QueueFile()
+	if err != nil {
+		return err
+	}
+
+	pending, err := LoadQueue(queueFile)
+	if err != nil || len(pending) == 0 {
+		return err
+	}
+
+	_, err = pushPendingWorklogs(client, config, queueFile, pending)
+	return err
+}
+
+// SaveSprintIssues remembers the sprint view for offline use
+func SaveSprintIssues(issues []SprintIssue) error {
+	sprintFile, err := SprintFile()
+	if err != nil {
+		return err
+	}
+
+	snapshot := sprintSnapshot{Synced: time.Now()}
+	for _, issue := range issues {
+		snapshot.Issues = append(snapshot.Issues, cachedSprintIssue{
+			Issue:    issue.issue,
+			Summary:  issue.summary,
+			Assignee: issue.assignee,
+		})
+	}
+
+	return writeJSONFile(sprintFile, &snapshot)
+}
+
+// OfflineSprintIssues returns the sprint view from the last online run
+func OfflineSprintIssues() (issues []SprintIssue, synced time.Time, err error) {
+	sprintFile, err := SprintFile()
+	if err != nil {
+		return
+	}
+
+	var snapshot sprintSnapshot
+	err = readJSONFile(sprintFile, &snapshot)
+	for _, issue := range snapshot.Issues {
+		issues = append(issues, SprintIssue{
+			issue:    issue.Issue,
+			summary:  issue.Summary,
+			assignee: issue.Assignee,
+		})
+	}
+	return issues, snapshot.Synced, err
+}
+
+// OfflineTimeEntries returns the time entries from the local cache.
+// A cache we can not read is no data, until the next online run
+// syncs it again.
+func OfflineTimeEntries(config ChronosConfig) ([]TimeEntry, time.Time, error) {
+	cacheFile, err := CacheFile()
+	if err != nil {
+		return []TimeEntry{}, time.Time{}, err
+	}
+
+	cache, err := LoadCache(cacheFile)
+	if err != nil {
+		log.Printf("[cache] Ignoring unreadable cache %s", err)
+		return []TimeEntry{}, time.Time{}, nil
+	}
+
+	return cache.TimeEntriesIn(config.ReportRange()), cache.Synced, nil
+}
+
+// OfflineStamp tells the reader how old the offline data is
+func OfflineStamp(synced time.Time) string {
+	if synced.IsZero() {
+		return "Offline: no data has been synced yet\n\n"
+	}
+	return fmt.Sprintf("Offline: showing data synced %s\n\n", synced.Format("2006-01-02 15:04"))
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+func TestSaveAndLoadQueue(t *testing.T) {
+	queueFile := filepath.Join(os.TempDir(), "chronos-queue", "queue.json")
+	defer os.RemoveAll(filepath.Dir(queueFile))
+
+	pending := []PendingWorklog{{Issue: issueA, Hours: 1, Minutes: 30, Comment: "On the train"}}
+	err := SaveQueue(queueFile, pending)
+	if err != nil {
+		t.Fatalf("Unable to save queue %s", err)
+	}
+
+	loaded, err := LoadQueue(queueFile)
+	if err != nil {
+		t.Fatalf("Unable to load queue %s", err)
+	}
+
+	if len(loaded) != 1 || loaded[0] != pending[0] {
+		t.Errorf("Wrong queue, got: %+v, want: %+v.", loaded, pending)
+	}
+}
+
+func TestPushPendingWorklogs(t *testing.T) {
+	queueFile := filepath.Join(os.TempDir(), "chronos-push", "queue.json")
+	defer os.RemoveAll(filepath.Dir(queueFile))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "AA-1/"):
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id": "1"}`))
+		case strings.Contains(r.URL.Path, "AA-2/"):
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := jira.NewClient(nil, server.URL)
+	pending := []PendingWorklog{
+		{Issue: "AA-1", Hours: 1, Started: time.Now()},
+		{Issue: "AA-2", Hours: 2},
+		{Issue: "AA-3", Hours: 3},
+	}
+
+	remaining, err := pushPendingWorklogs(client, DefaultConfig(), queueFile, pending)
+
+	if err != nil || len(remaining) != 1 || remaining[0].Issue != "AA-3" {
+		t.Errorf("Wrong remaining worklogs, got: %+v, %v", remaining, err)
+	}
+
+	saved, _ := LoadQueue(queueFile)
+	if len(saved) != 1 || saved[0].Issue != "AA-3" {
+		t.Errorf("Wrong saved queue, got: %+v", saved)
+	}
+}
+
+func TestPushPendingWorklogsStopsWhenSaveFails(t *testing.T) {
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted = append(posted, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "1"}`))
+	}))
+	defer server.Close()
+
+	// The queue can not be saved in a folder that is a file
+	blocker := filepath.Join(os.TempDir(), "chronos-push-blocked")
+	ioutil.WriteFile(blocker, []byte{}, 0600)
+	defer os.Remove(blocker)
+
+	client, _ := jira.NewClient(nil, server.URL)
+	pending := []PendingWorklog{{Issue: "AA-1", Hours: 1}, {Issue: "AA-2", Hours: 2}}
+
+	remaining, err := pushPendingWorklogs(client, DefaultConfig(), filepath.Join(blocker, "queue.json"), pending)
+
+	if err == nil || len(posted) != 1 {
+		t.Errorf("Nothing more should be logged once the queue can not be saved, got: %v, %v", posted, err)
+	}
+	if len(remaining) != 1 || remaining[0].Issue != "AA-2" {
+		t.Errorf("Wrong remaining worklogs, got: %+v", remaining)
+	}
+}
+
+func TestOfflineStamp(t *testing.T) {
+	synced := time.Date(2020, 1, 8, 14, 2, 0, 0, time.UTC)
+	expected := "Offline: showing data synced 2020-01-08 14:02\n\n"
+
+	if stamp := OfflineStamp(synced); stamp != expected {
+		t.Errorf("Wrong stamp, got: %q, want: %q.", stamp, expected)
+	}
+}
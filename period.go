@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// A DateRange is the period of a report, both dates included.
+// An empty To means there is no upper bound.
+type DateRange struct {
+	From string
+	To   string
+}
+
+// Contains tells if a date is within the range
+func (r DateRange) Contains(date string) bool {
+	return date >= r.From && (r.To == "" || date <= r.To)
+}
+
+// Validate checks that the dates are dates and in the right order
+func (r DateRange) Validate() error {
+	for _, date := range []string{r.From, r.To} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			return fmt.Errorf("%s is not a date like 2006-01-02", date)
+		}
+	}
+
+	if r.From != "" && r.To != "" && r.From > r.To {
+		return fmt.Errorf("from %s is after to %s", r.From, r.To)
+	}
+	return nil
+}
+
+// Periods are the shorthands that can be picked with --period
+var Periods = []string{"this-week", "last-week", "this-month", "last-month", "this-quarter", "last-quarter", "this-year", "last-year"}
+
+// PeriodRange returns the date range of a period relative to now
+func PeriodRange(period string, now time.Time) (DateRange, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	quarter := month.AddDate(0, -((int(now.Month()) - 1) % 3), 0)
+	year := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+
+	// Each period is a start and the start of the next one
+	var start, next time.Time
+	switch period {
+	case "this-week":
+		start, next = monday, monday.AddDate(0, 0, 7)
+	case "last-week":
+		start, next = monday.AddDate(0, 0, -7), monday
+	case "this-month":
+		start, next = month, month.AddDate(0, 1, 0)
+	case "last-month":
+		start, next = month.AddDate(0, -1, 0), month
+	case "this-quarter":
+		start, next = quarter, quarter.AddDate(0, 3, 0)
+	case "last-quarter":
+		start, next = quarter.AddDate(0, -3, 0), quarter
+	case "this-year":
+		start, next = year, year.AddDate(1, 0, 0)
+	case "last-year":
+		start, next = year.AddDate(-1, 0, 0), year
+	default:
+		return DateRange{}, fmt.Errorf("unknown period %s", period)
+	}
+
+	return DateRange{
+		From: start.Format(dateLayout),
+		To:   next.AddDate(0, 0, -1).Format(dateLayout),
+	}, nil
+}
+
+// ReportRange is the date range of the report. Without a
+// configured range, it is WeeksLookback weeks back until today.
+func (c ChronosConfig) ReportRange() DateRange {
+	dates := c.Range
+	if dates.From == "" {
+		dates.From = CalcPassedDate(c).Format(dateLayout)
+	}
+	if dates.To == "" {
+		dates.To = time.Now().In(c.Location()).Format(dateLayout)
+	}
+	return dates
+}
+
+// ValidateRange checks the configured range, and that it still is a
+// range once the missing dates are filled in. A --to before the weeks
+// looked back, or a --from after today, would leave the report empty.
+func (c ChronosConfig) ValidateRange() error {
+	if err := c.Range.Validate(); err != nil {
+		return err
+	}
+	if err := c.ReportRange().Validate(); err != nil {
+		return fmt.Errorf("%s, give both --from and --to", err)
+	}
+	return nil
+}
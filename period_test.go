@@ -0,0 +1,116 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodRange(t *testing.T) {
+	now := time.Date(2026, 1, 14, 10, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		period   string
+		from, to string
+	}{
+		{"this-week", "2026-01-12", "2026-01-18"},
+		{"last-week", "2026-01-05", "2026-01-11"},
+		{"this-month", "2026-01-01", "2026-01-31"},
+		{"last-month", "2025-12-01", "2025-12-31"},
+		{"this-quarter", "2026-01-01", "2026-03-31"},
+		{"last-quarter", "2025-10-01", "2025-12-31"},
+		{"this-year", "2026-01-01", "2026-12-31"},
+		{"last-year", "2025-01-01", "2025-12-31"},
+	}
+
+	for _, c := range cases {
+		dates, err := PeriodRange(c.period, now)
+		if err != nil {
+			t.Fatalf("Unable to get period %s: %s", c.period, err)
+		}
+		if dates.From != c.from || dates.To != c.to {
+			t.Errorf("Wrong %s, got: %s to %s, want: %s to %s.", c.period, dates.From, dates.To, c.from, c.to)
+		}
+	}
+}
+
+func TestPeriodRangeQuarterAndLeapYear(t *testing.T) {
+	dates, _ := PeriodRange("this-quarter", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC))
+	if dates.From != "2026-10-01" || dates.To != "2026-12-31" {
+		t.Errorf("Wrong quarter, got: %s to %s.", dates.From, dates.To)
+	}
+
+	dates, _ = PeriodRange("last-month", time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC))
+	if dates.From != "2024-02-01" || dates.To != "2024-02-29" {
+		t.Errorf("Wrong month, got: %s to %s.", dates.From, dates.To)
+	}
+}
+
+func TestPeriodRangeUnknown(t *testing.T) {
+	if _, err := PeriodRange("next-decade", time.Now()); err == nil {
+		t.Errorf("Unknown periods should fail")
+	}
+}
+
+func TestDateRangeValidate(t *testing.T) {
+	if err := (DateRange{From: "2026-09-01", To: "2026-09-30"}).Validate(); err != nil {
+		t.Errorf("Valid range failed: %s", err)
+	}
+	if err := (DateRange{From: "2026-09-30", To: "2026-09-01"}).Validate(); err == nil {
+		t.Errorf("From after to should fail")
+	}
+	if err := (DateRange{From: "1/9/2026"}).Validate(); err == nil {
+		t.Errorf("Bad dates should fail")
+	}
+}
+
+func TestDateRangeContains(t *testing.T) {
+	dates := DateRange{From: "2026-09-01", To: "2026-09-30"}
+	if !dates.Contains("2026-09-01") || !dates.Contains("2026-09-30") || dates.Contains("2026-10-01") {
+		t.Errorf("Both dates should be included, and nothing after")
+	}
+
+	open := DateRange{From: "2026-09-01"}
+	if !open.Contains("2030-01-01") || open.Contains("2026-08-31") {
+		t.Errorf("An empty to should have no upper bound")
+	}
+}
+
+func TestReportRangeDefault(t *testing.T) {
+	config := DefaultConfig()
+	dates := config.ReportRange()
+
+	if dates.From != CalcPassedDate(config).Format(dateLayout) {
+		t.Errorf("Wrong default from, got: %s.", dates.From)
+	}
+	if dates.To != time.Now().In(config.Location()).Format(dateLayout) {
+		t.Errorf("Wrong default to, got: %s.", dates.To)
+	}
+}
+
+func TestValidateRange(t *testing.T) {
+	config := DefaultConfig()
+	config.Range = DateRange{To: "2018-01-04"}
+	if err := config.ValidateRange(); err == nil {
+		t.Errorf("To before the weeks looked back should fail")
+	}
+
+	config.Range = DateRange{From: time.Now().AddDate(0, 0, 7).Format(dateLayout)}
+	if err := config.ValidateRange(); err == nil {
+		t.Errorf("From after today should fail")
+	}
+
+	config.Range = DateRange{From: "2018-01-01"}
+	if err := config.ValidateRange(); err != nil {
+		t.Errorf("Valid range failed: %s", err)
+	}
+}
+
+func TestWorkCalendarRange(t *testing.T) {
+	config := DefaultConfig()
+	config.Range = DateRange{From: "2018-01-03", To: "2018-01-04"}
+	calendar, _ := NewWorkCalendar(config)
+
+	if expected := calendar.WeekExpected("2018-01-01"); expected != 2*7.4 {
+		t.Errorf("Only days in the range are expected, got: %.2f, want: %.2f.", expected, 2*7.4)
+	}
+}
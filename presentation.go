@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 // Command represent a low-level presentation command
@@ -14,18 +17,25 @@ type Command interface {
 type clearWeek struct{}
 type clearDate struct{}
 type clearIssue struct{}
-type newWeek struct{ week int }
+type newWeek struct{ week ISOWeek }
 type newDate struct{ date string }
 type newIssue struct{ issue, summary string }
 type summaryDate struct{}
 type summaryWeek struct{}
 type noteHours struct {
-	hours   float32
-	comment string
+	hours     float32
+	comment   string
+	started   time.Time
+	worklogID string
 }
 type printNewIssue struct{}
 type printSameIssue struct{}
 type printIssueSummary struct{ issue, summary string }
+type missingDate struct{ expected float32 }
+type clearSection struct{}
+type newSection struct{ title string }
+type summarySection struct{}
+type printDateTotal struct{ date string }
 
 func (clearWeek) isCommand()         {}
 func (clearDate) isCommand()         {}
@@ -39,6 +49,11 @@ func (noteHours) isCommand()         {}
 func (printNewIssue) isCommand()     {}
 func (printSameIssue) isCommand()    {}
 func (printIssueSummary) isCommand() {}
+func (missingDate) isCommand()       {}
+func (clearSection) isCommand()      {}
+func (newSection) isCommand()        {}
+func (summarySection) isCommand()    {}
+func (printDateTotal) isCommand()    {}
 
 // ExtractIssueSummaries will take many timeEntries and extract their summaries
 func ExtractIssueSummaries(timeEntries []TimeEntry) (issues []string, summaries map[string]string) {
@@ -60,88 +75,147 @@ func ExtractIssueSummaries(timeEntries []TimeEntry) (issues []string, summaries
 // BuildCommands will take time entries and create low-level commands.
 // We do it like this to avoid subtle bugs
 func BuildCommands(timeEntries []TimeEntry) (commands []Command) {
-	sort.Slice(timeEntries, func(i, j int) bool {
-		// If date is the same, sort on issue
-		if timeEntries[i].Date == timeEntries[j].Date {
-			return timeEntries[i].Issue < timeEntries[j].Issue
+	return BuildCommandsWithGaps(timeEntries, nil)
+}
+
+// BuildCommandsWithGaps is BuildCommands with a placeholder
+// for every gap that has no time entries at all
+func BuildCommandsWithGaps(timeEntries []TimeEntry, gaps []Gap) (commands []Command) {
+	return buildCommands(timeEntries, gaps, byWeek)
+}
+
+// A grouping splits the report into sections, weeks unless the
+// report is grouped by something else
+type grouping struct {
+	// key orders the sections and tells them apart
+	key func(entry TimeEntry) string
+	// open starts a section, given all of its rows
+	open func(rows []TimeEntry) Command
+	// close ends a section, after its last date
+	close []Command
+	// compact lists each date as a single line
+	compact bool
+}
+
+// byWeek is how BuildCommands groups the report
+var byWeek = grouping{
+	key: func(entry TimeEntry) string {
+		return entry.Week.String()
+	},
+	open: func(rows []TimeEntry) Command {
+		return newWeek{week: rows[0].Week}
+	},
+	close: []Command{summaryWeek{}, clearWeek{}},
+}
+
+// buildCommands is the state machine behind all the reports. It walks
+// the time entries, sorted by section, date and issue, and notes where
+// a new section, date or issue starts.
+func buildCommands(timeEntries []TimeEntry, gaps []Gap, group grouping) (commands []Command) {
+	sortBySection(timeEntries, group)
+
+	// Placeholders are rows without an issue, sorted in with the rest
+	placeholders := make(map[string]float32)
+	rows := append([]TimeEntry{}, timeEntries...)
+	for _, gap := range gaps {
+		if gap.Logged == 0 {
+			placeholders[gap.Date] = gap.Expected
+			rows = append(rows, TimeEntry{Date: gap.Date, Week: gap.Week})
 		}
+	}
+	sortBySection(rows, group)
 
-		// However, sort primarily on date
-		return timeEntries[i].Date < timeEntries[j].Date
-	})
+	if len(rows) == 0 {
+		return
+	}
 
-	var currentWeek = 0
+	var currentSection = ""
 	var currentDate = ""
 	var currentIssue = ""
 
-	for _, timeEntry := range timeEntries {
-		if timeEntry.Week != currentWeek {
-			if currentWeek != 0 {
-				commands = append(commands, summaryDate{})
-				commands = append(commands, summaryWeek{})
+	hours := func(timeEntry TimeEntry) Command {
+		return noteHours{hours: timeEntry.Hours, comment: timeEntry.Comment, started: timeEntry.Started, worklogID: timeEntry.WorklogID}
+	}
+
+	// Close the date the way it was opened, a compact
+	// date is a single line printed after its hours
+	endDate := func() []Command {
+		if group.compact {
+			return []Command{printDateTotal{date: currentDate}, clearIssue{}, clearDate{}}
+		}
+		return []Command{summaryDate{}, clearIssue{}, clearDate{}}
+	}
 
-				commands = append(commands, clearIssue{})
-				commands = append(commands, clearDate{})
-				commands = append(commands, clearWeek{})
+	// The first row of a date, either an issue or a gap
+	firstRow := func(timeEntry TimeEntry) []Command {
+		if expected, ok := placeholders[timeEntry.Date]; ok {
+			if group.compact {
+				return []Command{missingDate{expected: expected}}
 			}
+			return []Command{newDate{date: timeEntry.Date}, missingDate{expected: expected}}
+		}
+		if group.compact {
+			return []Command{hours(timeEntry)}
+		}
+		return []Command{
+			newDate{date: timeEntry.Date},
+			newIssue{issue: timeEntry.Issue, summary: timeEntry.Summary},
+			hours(timeEntry),
+			printNewIssue{},
+		}
+	}
 
-			commands = append(commands, newWeek{week: timeEntry.Week})
-			commands = append(commands, newDate{date: timeEntry.Date})
-			commands = append(commands, newIssue{issue: timeEntry.Issue, summary: timeEntry.Summary})
+	for i, timeEntry := range rows {
+		key := group.key(timeEntry)
 
-			commands = append(commands, noteHours{hours: timeEntry.Hours, comment: timeEntry.Comment})
-			commands = append(commands, printNewIssue{})
+		if i == 0 || key != currentSection {
+			if i > 0 {
+				commands = append(commands, endDate()...)
+				commands = append(commands, group.close...)
+			}
+
+			end := i
+			for end < len(rows) && group.key(rows[end]) == key {
+				end++
+			}
+			commands = append(commands, group.open(rows[i:end]))
+			commands = append(commands, firstRow(timeEntry)...)
 
-			currentWeek = timeEntry.Week
+			currentSection = key
 			currentDate = timeEntry.Date
 			currentIssue = timeEntry.Issue
 
 		} else if timeEntry.Date != currentDate {
-			commands = append(commands, summaryDate{})
-
-			commands = append(commands, clearIssue{})
-			commands = append(commands, clearDate{})
-
-			commands = append(commands, newDate{date: timeEntry.Date})
-			commands = append(commands, newIssue{issue: timeEntry.Issue, summary: timeEntry.Summary})
-
-			commands = append(commands, noteHours{hours: timeEntry.Hours, comment: timeEntry.Comment})
-			commands = append(commands, printNewIssue{})
+			commands = append(commands, endDate()...)
+			commands = append(commands, firstRow(timeEntry)...)
 
 			currentDate = timeEntry.Date
 			currentIssue = timeEntry.Issue
 
+		} else if group.compact {
+			commands = append(commands, hours(timeEntry))
+
 		} else if timeEntry.Issue != currentIssue {
 			commands = append(commands, clearIssue{})
 
 			commands = append(commands, newIssue{issue: timeEntry.Issue, summary: timeEntry.Summary})
 
-			commands = append(commands, noteHours{hours: timeEntry.Hours, comment: timeEntry.Comment})
+			commands = append(commands, hours(timeEntry))
 			commands = append(commands, printNewIssue{})
 
 			currentIssue = timeEntry.Issue
 		} else {
-			commands = append(commands, noteHours{hours: timeEntry.Hours, comment: timeEntry.Comment})
+			commands = append(commands, hours(timeEntry))
 			commands = append(commands, printSameIssue{})
 		}
 	}
 
 	// Always finish by clearing and showing missing summaries
-	if len(commands) > 0 {
-		commands = append(commands, clearIssue{})
-	}
-
-	if len(commands) > 0 {
-		commands = append(commands, summaryDate{})
-		commands = append(commands, clearDate{})
-	}
-
-	if len(commands) > 0 {
-		commands = append(commands, summaryWeek{})
-		commands = append(commands, clearWeek{})
-	}
+	commands = append(commands, endDate()...)
+	commands = append(commands, group.close...)
 
-	if len(commands) > 0 {
+	// A compact section already is an issue
+	if !group.compact {
 		issues, summaries := ExtractIssueSummaries(timeEntries)
 
 		for _, issue := range issues {
@@ -153,45 +227,166 @@ func BuildCommands(timeEntries []TimeEntry) (commands []Command) {
 	return
 }
 
+// sortBySection sorts on the section, then on date and issue
+func sortBySection(timeEntries []TimeEntry, group grouping) {
+	sort.SliceStable(timeEntries, func(i, j int) bool {
+		keyI, keyJ := group.key(timeEntries[i]), group.key(timeEntries[j])
+		if keyI != keyJ {
+			return keyI < keyJ
+		}
+		if timeEntries[i].Date != timeEntries[j].Date {
+			return timeEntries[i].Date < timeEntries[j].Date
+		}
+		return timeEntries[i].Issue < timeEntries[j].Issue
+	})
+}
+
+// TextOptions controls the optional parts of the text report
+type TextOptions struct {
+	// Legend lists all issues with their totals at the end
+	Legend bool
+	// ShortLines leaves the summaries to the legend
+	ShortLines bool
+	// BaseURL of Jira, used to link to the issues in the legend
+	BaseURL string
+	// Comments shows the worklog comments, with their IDs, under each line
+	Comments bool
+	// Calendar knows the expected hours, nil hides the
+	// expected hours and the flex-time balance
+	Calendar *WorkCalendar
+}
+
+// commentWidth is where long comments are wrapped
+const commentWidth = 60
+
+// wrapText splits text into lines of at most width characters,
+// keeping the line breaks the author wrote but not empty lines
+func wrapText(text string, width int) (lines []string) {
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width:
+				lines = append(lines, line)
+				line = word
+			default:
+				line += " " + word
+			}
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return
+}
+
+// writeComment prints a comment wrapped and aligned
+// under the hours of the issue line above it
+func writeComment(out *bytes.Buffer, issue, comment string) {
+	indent := strings.Repeat(" ", len(issue)+2+6+1)
+	for _, line := range wrapText(comment, commentWidth) {
+		out.WriteString(fmt.Sprintf("\t%s// %s\n", indent, line))
+	}
+}
+
+// worklogNote leads a comment with the ID of its worklog,
+// which chronos worklog edit and delete take
+func worklogNote(worklogID, comment string) string {
+	if worklogID == "" {
+		return comment
+	}
+	return strings.TrimSpace(fmt.Sprintf("#%s %s", worklogID, comment))
+}
+
+// DefaultTextOptions shows the legend, but no links
+func DefaultTextOptions() TextOptions {
+	return TextOptions{Legend: true}
+}
+
 // PrettyPrint converts commands to bytes buffer.
 // Instead of printing directly to stdout we make
 // the code more testable using a bytes.Buffer that
 // we can easily inspect
 func PrettyPrint(commands []Command) (out bytes.Buffer) {
-	showComments := false
+	return PrettyPrintWithOptions(commands, DefaultTextOptions())
+}
+
+// PrettyPrintWithOptions is PrettyPrint with control
+// over the optional parts of the report
+func PrettyPrintWithOptions(commands []Command, options TextOptions) (out bytes.Buffer) {
+	var flex float32 = 0.0
+	var sectionTotal float32 = 0.0
 	var weekTotal float32 = 0.0
 	var dateTotal float32 = 0.0
 	var issueTotal float32 = 0.0
 	var issueHours float32 = 0.0
 
-	var week int = 0
+	var week ISOWeek
+	var weekStarted bool = false
 	var date string = ""
 	var issue string = ""
 	var issueText string = ""
 	var comment string = ""
+	var worklogID string = ""
+
+	// A compact date prints the comments of all its hours at once
+	var dateComments []string
+
+	// Totals per issue over the whole period, for the legend
+	issueTotals := make(map[string]float32)
+	legendStarted := false
 
 	for _, command := range commands {
 		switch cmd := command.(type) {
 
+		case clearSection:
+			sectionTotal = 0.0
 		case clearWeek:
 			weekTotal = 0.0
 		case clearDate:
 			dateTotal = 0.0
 			issueText = ""
+			dateComments = nil
 		case clearIssue:
 			issueTotal = 0.0
 			issueHours = 0.0
 
 		case newWeek:
 			week = cmd.week
+			weekStarted = true
 			out.WriteString("===========================\n")
-			out.WriteString(fmt.Sprintf("Week %2d\n", week))
+			out.WriteString(fmt.Sprintf("Week %s\n", week))
 			out.WriteString("===========================\n")
 			out.WriteString("\n")
 
+		case newSection:
+			out.WriteString("===========================\n")
+			out.WriteString(fmt.Sprintf("%s\n", cmd.title))
+			out.WriteString("===========================\n")
+			out.WriteString("\n")
+
+		case summarySection:
+			out.WriteString("\n")
+			out.WriteString(fmt.Sprintf("\tTotal:   %6.2f\n", sectionTotal))
+			out.WriteString("\n")
+
+		case printDateTotal:
+			out.WriteString(fmt.Sprintf("\t%s: %6.2f\n", cmd.date, dateTotal))
+			if options.Comments {
+				for _, comment := range dateComments {
+					writeComment(&out, cmd.date, comment)
+				}
+			}
+
 		case newDate:
 			date = cmd.date
-			out.WriteString(fmt.Sprintf("%s\n", date))
+			if options.Calendar.Enabled() && options.Calendar.DayOff(date) {
+				out.WriteString(fmt.Sprintf("%s (day off)\n", date))
+			} else {
+				out.WriteString(fmt.Sprintf("%s\n", date))
+			}
 
 		case newIssue:
 			issue = cmd.issue
@@ -200,44 +395,77 @@ func PrettyPrint(commands []Command) (out bytes.Buffer) {
 		case summaryDate:
 			if date != "" {
 				out.WriteString("\t------------------\n")
-				out.WriteString(fmt.Sprintf("\t\t %6.2f\n", dateTotal))
+				if options.Calendar.Enabled() {
+					out.WriteString(fmt.Sprintf("\t\t %6.2f of %6.2f\n", dateTotal, options.Calendar.Expected(date)))
+				} else {
+					out.WriteString(fmt.Sprintf("\t\t %6.2f\n", dateTotal))
+				}
 			}
 
 		case summaryWeek:
-			if week > 0 {
+			if weekStarted {
 				out.WriteString("\n")
 				out.WriteString(fmt.Sprintf("\tTotal:   %6.2f\n", weekTotal))
+				if options.Calendar.Enabled() {
+					expected := options.Calendar.WeekExpected(date)
+					delta := weekTotal - expected
+					flex += delta
+					out.WriteString(fmt.Sprintf("\tExpected:%6.2f\n", expected))
+					out.WriteString(fmt.Sprintf("\tDelta:   %+6.2f\n", delta))
+					out.WriteString(fmt.Sprintf("\tFlex:    %+6.2f\n", flex))
+				}
 				out.WriteString("\n")
 			}
 
 		// note down the time for an issue
 		case noteHours:
+			sectionTotal += cmd.hours
 			weekTotal += cmd.hours
 			dateTotal += cmd.hours
 			issueTotal += cmd.hours
 			issueHours = cmd.hours
 			comment = cmd.comment
+			worklogID = cmd.worklogID
+			dateComments = append(dateComments, worklogNote(cmd.worklogID, cmd.comment))
+			issueTotals[issue] += cmd.hours
 
 		case printNewIssue:
 			if issue != "" {
-				if comment != "" && showComments {
-					out.WriteString(fmt.Sprintf("\t%s: %6.2f %s // %s\n", issue, issueHours, issueText, comment))
-				} else {
-					out.WriteString(fmt.Sprintf("\t%s: %6.2f %s\n", issue, issueHours, issueText))
+				text := issueText
+				if options.ShortLines {
+					text = ""
+				}
+				line := strings.TrimRight(fmt.Sprintf("\t%s: %6.2f %s", issue, issueHours, text), " ")
+				out.WriteString(line + "\n")
+				if options.Comments {
+					writeComment(&out, issue, worklogNote(worklogID, comment))
 				}
 			}
 
 		case printSameIssue:
-			if comment != "" && showComments {
-				out.WriteString(fmt.Sprintf("\t    \\--: %6.2f // %s\n", issueHours, comment))
-			} else {
-				out.WriteString(fmt.Sprintf("\t    \\--: %6.2f\n", issueHours))
+			out.WriteString(fmt.Sprintf("\t    \\--: %6.2f\n", issueHours))
+			if options.Comments {
+				writeComment(&out, issue, worklogNote(worklogID, comment))
 			}
 
+		case missingDate:
+			out.WriteString(fmt.Sprintf("\t!! gap: %6.2f not logged\n", cmd.expected))
+
 		case printIssueSummary:
-			// issue := cmd.issue
-			// summary := cmd.summary
-			// TODO: out.WriteString(fmt.Sprintf("%s: %s\n", issue, summary))
+			if !options.Legend {
+				break
+			}
+			if !legendStarted {
+				out.WriteString("===========================\n")
+				out.WriteString("Issues\n")
+				out.WriteString("===========================\n")
+				out.WriteString("\n")
+				legendStarted = true
+			}
+			out.WriteString(fmt.Sprintf("\t%s: %6.2f %s\n", cmd.issue, issueTotals[cmd.issue], cmd.summary))
+			if url := issueURL(options.BaseURL, cmd.issue); url != "" {
+				out.WriteString(fmt.Sprintf("\t\t %s\n", url))
+			}
 		}
 	}
 	return
@@ -248,15 +476,28 @@ func PrettyPrint(commands []Command) (out bytes.Buffer) {
 // the code more testable using a bytes.Buffer that
 // we can easily inspect
 func PrettyPrintBrief(commands []Command) (out bytes.Buffer) {
+	return PrettyPrintBriefWithOptions(commands, TextOptions{})
+}
+
+// PrettyPrintBriefWithOptions is PrettyPrintBrief with the
+// expected hours and flex-time balance when there is a calendar
+func PrettyPrintBriefWithOptions(commands []Command, options TextOptions) (out bytes.Buffer) {
+	var flex float32 = 0.0
+	var sectionTotal float32 = 0.0
 	var weekTotal float32 = 0.0
 	var dateTotal float32 = 0.0
 	var issueTotal float32 = 0.0
 
-	var week int = 0
+	var title string = ""
+	var week ISOWeek
+	var weekStarted bool = false
+	var date string = ""
 
 	for _, command := range commands {
 		switch cmd := command.(type) {
 
+		case clearSection:
+			sectionTotal = 0.0
 		case clearWeek:
 			weekTotal = 0.0
 		case clearDate:
@@ -266,14 +507,30 @@ func PrettyPrintBrief(commands []Command) (out bytes.Buffer) {
 
 		case newWeek:
 			week = cmd.week
+			weekStarted = true
+
+		case newSection:
+			title = cmd.title
+
+		case summarySection:
+			out.WriteString(fmt.Sprintf("%s: %6.2f\n", title, sectionTotal))
+
+		case newDate:
+			date = cmd.date
 
 		case summaryWeek:
-			if week > 0 {
-				out.WriteString(fmt.Sprintf("Week [%2d]: %6.2f\n", week, weekTotal))
+			if weekStarted && options.Calendar.Enabled() {
+				expected := options.Calendar.WeekExpected(date)
+				delta := weekTotal - expected
+				flex += delta
+				out.WriteString(fmt.Sprintf("Week [%s]: %6.2f of %6.2f %+7.2f flex %+7.2f\n", week, weekTotal, expected, delta, flex))
+			} else if weekStarted {
+				out.WriteString(fmt.Sprintf("Week [%s]: %6.2f\n", week, weekTotal))
 			}
 
 		// note down the time for an issue
 		case noteHours:
+			sectionTotal += cmd.hours
 			weekTotal += cmd.hours
 			dateTotal += cmd.hours
 			issueTotal += cmd.hours
@@ -284,14 +541,10 @@ func PrettyPrintBrief(commands []Command) (out bytes.Buffer) {
 
 // Print will pretty print the time entries
 func Print(timeEntries []TimeEntry) {
-	commands := BuildCommands(timeEntries)
-	output := PrettyPrint(commands)
-	fmt.Printf(output.String())
+	Render(textRenderer{options: DefaultTextOptions()}, timeEntries)
 }
 
 // PrintBrief prints a brief worklog
 func PrintBrief(timeEntries []TimeEntry) {
-	commands := BuildCommands(timeEntries)
-	output := PrettyPrintBrief(commands)
-	fmt.Printf(output.String())
+	Render(briefRenderer{}, timeEntries)
 }
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 var issueA = "AA-1234"
@@ -13,17 +14,18 @@ var summaryA = "Summary of issue A"
 var summaryB = "Summary of issue B"
 
 var timeEntry1 = TimeEntry{
-	Week:     1,
+	Week:     ISOWeek{2018, 1},
 	Date:     "2018-01-01",
 	Issue:    issueA,
 	Summary:  summaryA,
 	Employee: "maxx",
 	Hours:    1.0,
 	Comment:  "My Comment 111",
+	Started:  time.Date(2018, 1, 1, 9, 0, 0, 0, time.UTC),
 }
 
 var timeEntry2 = TimeEntry{
-	Week:     1,
+	Week:     ISOWeek{2018, 1},
 	Date:     "2018-01-01",
 	Issue:    issueB,
 	Summary:  summaryB,
@@ -33,7 +35,7 @@ var timeEntry2 = TimeEntry{
 }
 
 var timeEntry3 = TimeEntry{
-	Week:     2,
+	Week:     ISOWeek{2018, 2},
 	Date:     "2018-01-08",
 	Issue:    issueB,
 	Summary:  summaryB,
@@ -43,7 +45,7 @@ var timeEntry3 = TimeEntry{
 }
 
 var timeEntry4 = TimeEntry{
-	Week:     2,
+	Week:     ISOWeek{2018, 2},
 	Date:     "2018-01-09",
 	Issue:    issueB,
 	Summary:  summaryB,
@@ -236,3 +238,185 @@ func TestExtractIssueSummariesTwoIssues(t *testing.T) {
 		t.Errorf("Wrong summary, got: %s, exprected: %s\n", summaryB, expectedB)
 	}
 }
+
+func helpPrettyPrintJSON(commands []Command, goldenFilename string) (output, expected string) {
+	outputBuffer := PrettyPrintJSON(commands)
+	golden := filepath.Join("testdata", goldenFilename)
+	expectedBytes, _ := ioutil.ReadFile(golden)
+
+	output = strings.TrimRight(outputBuffer.String(), "\n")
+	expected = strings.TrimRight(string(expectedBytes), "\n")
+
+	return
+}
+
+func TestPrettyPrintJSONEmpty(t *testing.T) {
+	commands := BuildCommands([]TimeEntry{})
+	output, expected := helpPrettyPrintJSON(commands, "empty.json")
+
+	if output != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output, expected)
+	}
+}
+
+func TestPrettyPrintJSONTwoEntries(t *testing.T) {
+	commands := BuildCommands([]TimeEntry{timeEntry1, timeEntry1})
+	output, expected := helpPrettyPrintJSON(commands, "timeEntry11.json")
+
+	if output != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output, expected)
+	}
+}
+
+func TestPrettyPrintJSON1234(t *testing.T) {
+	commands := BuildCommands([]TimeEntry{timeEntry1, timeEntry2, timeEntry3, timeEntry4})
+	output, expected := helpPrettyPrintJSON(commands, "timeEntry1234.json")
+
+	if output != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output, expected)
+	}
+}
+
+func TestPrettyPrintShortLinesWithLinks(t *testing.T) {
+	commands := BuildCommands([]TimeEntry{timeEntry1, timeEntry2, timeEntry3, timeEntry4})
+	options := TextOptions{Legend: true, ShortLines: true, BaseURL: "https://myJira.atlassian.net"}
+	outputBuffer := PrettyPrintWithOptions(commands, options)
+
+	expectedBytes, _ := ioutil.ReadFile(filepath.Join("testdata", "timeEntry1234-short.txt"))
+	output := strings.TrimRight(outputBuffer.String(), "\n")
+	expected := strings.TrimRight(string(expectedBytes), "\n")
+
+	if output != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output, expected)
+	}
+}
+
+func TestPrettyPrintWithoutLegend(t *testing.T) {
+	commands := BuildCommands([]TimeEntry{timeEntry1})
+	output := PrettyPrintWithOptions(commands, TextOptions{})
+
+	if strings.Contains(output.String(), "Issues") {
+		t.Errorf("Legend should be hidden, got:\n%s", output.String())
+	}
+}
+
+func TestPrettyPrintComments(t *testing.T) {
+	long := timeEntry2
+	long.Comment = "Paired with the backend team on the flaky login test, it turned out to be a race in the session cache\nFollow up in AA-1236"
+
+	commands := BuildCommands([]TimeEntry{timeEntry1, timeEntry1, long})
+	options := TextOptions{Comments: true}
+	outputBuffer := PrettyPrintWithOptions(commands, options)
+
+	expectedBytes, _ := ioutil.ReadFile(filepath.Join("testdata", "timeEntry112-comments.txt"))
+	output := strings.TrimRight(outputBuffer.String(), "\n")
+	expected := strings.TrimRight(string(expectedBytes), "\n")
+
+	if output != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output, expected)
+	}
+}
+
+func TestPrettyPrintCommentsWorklogIDs(t *testing.T) {
+	first := timeEntry1
+	first.WorklogID = "10100"
+	second := timeEntry1
+	second.WorklogID = "10101"
+	second.Comment = ""
+
+	output := PrettyPrintWithOptions(BuildCommands([]TimeEntry{first, second}), TextOptions{Comments: true})
+
+	expected := "\tAA-1234:   1.00 Summary of issue A\n" +
+		"\t                // #10100 My Comment 111\n" +
+		"\t    \\--:   1.00\n" +
+		"\t                // #10101\n"
+	if !strings.Contains(output.String(), expected) {
+		t.Errorf("Wrong output, got:\n%s\nwant it to contain:\n%s\n", output.String(), expected)
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	lines := wrapText("one two three\n\nfour five", 9)
+	expected := []string{"one two", "three", "four five"}
+
+	if strings.Join(lines, "|") != strings.Join(expected, "|") {
+		t.Errorf("Wrong wrapping, got: %q, want: %q.", lines, expected)
+	}
+}
+
+func weeklyCalendar(hoursPerWeek float64) *WorkCalendar {
+	config := DefaultConfig()
+	config.HoursPerWeek = hoursPerWeek
+	calendar, _ := NewWorkCalendar(config)
+	calendar.Until = ""
+	return calendar
+}
+
+func TestPrettyPrintFlexTime(t *testing.T) {
+	commands := BuildCommands([]TimeEntry{timeEntry1, timeEntry2, timeEntry3, timeEntry4})
+	outputBuffer := PrettyPrintWithOptions(commands, TextOptions{Calendar: weeklyCalendar(5)})
+
+	expectedBytes, _ := ioutil.ReadFile(filepath.Join("testdata", "timeEntry1234-flex.txt"))
+	output := strings.TrimRight(outputBuffer.String(), "\n")
+	expected := strings.TrimRight(string(expectedBytes), "\n")
+
+	if output != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output, expected)
+	}
+}
+
+func TestPrettyPrintBriefFlexTime(t *testing.T) {
+	commands := BuildCommands([]TimeEntry{timeEntry1, timeEntry2, timeEntry3, timeEntry4})
+	output := PrettyPrintBriefWithOptions(commands, TextOptions{Calendar: weeklyCalendar(5)})
+
+	expected := "Week [2018-W01]:   3.00 of   5.00   -2.00 flex   -2.00\n" +
+		"Week [2018-W02]:   7.00 of   5.00   +2.00 flex   +0.00\n"
+	if output.String() != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output.String(), expected)
+	}
+}
+
+func newWeeks(commands []Command) (weeks []string) {
+	for _, command := range commands {
+		if cmd, ok := command.(newWeek); ok {
+			weeks = append(weeks, cmd.week.String())
+		}
+	}
+	return
+}
+
+func TestCommandBuilderSameWeekNumberInTwoYears(t *testing.T) {
+	lastYear := timeEntry1
+	lastYear.Date = "2017-01-02"
+	lastYear.Week = ISOWeek{2017, 1}
+
+	weeks := newWeeks(BuildCommands([]TimeEntry{timeEntry1, lastYear}))
+	if strings.Join(weeks, " ") != "2017-W01 2018-W01" {
+		t.Errorf("Week 1 of two years should not be merged, got: %s.", weeks)
+	}
+}
+
+func TestCommandBuilderWeekOverNewYear(t *testing.T) {
+	newYearsEve := timeEntry1
+	newYearsEve.Date = "2026-12-31"
+	newYearsEve.Week = ISOWeek{2026, 53}
+	newYearsDay := timeEntry2
+	newYearsDay.Date = "2027-01-01"
+	newYearsDay.Week = ISOWeek{2026, 53}
+	nextWeek := timeEntry3
+	nextWeek.Date = "2027-01-04"
+	nextWeek.Week = ISOWeek{2027, 1}
+
+	commands := BuildCommands([]TimeEntry{nextWeek, newYearsDay, newYearsEve})
+	weeks := newWeeks(commands)
+	if strings.Join(weeks, " ") != "2026-W53 2027-W01" {
+		t.Errorf("Week 53 should span New Year, got: %s.", weeks)
+	}
+
+	output := PrettyPrintBrief(commands)
+	expected := "Week [2026-W53]:   3.00\n" +
+		"Week [2027-W01]:   3.00\n"
+	if output.String() != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output.String(), expected)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A Renderer turns the presentation commands into a finished report
+type Renderer interface {
+	Render(commands []Command) bytes.Buffer
+}
+
+type textRenderer struct{ options TextOptions }
+type briefRenderer struct{ options TextOptions }
+type jsonRenderer struct{}
+
+func (r textRenderer) Render(commands []Command) bytes.Buffer {
+	return PrettyPrintWithOptions(commands, r.options)
+}
+
+func (r briefRenderer) Render(commands []Command) bytes.Buffer {
+	return PrettyPrintBriefWithOptions(commands, r.options)
+}
+
+func (jsonRenderer) Render(commands []Command) bytes.Buffer {
+	return PrettyPrintJSON(commands)
+}
+
+// Formats are the report formats that can be picked with --format
+var Formats = []string{"text", "json", "csv", "markdown", "html"}
+
+// ValidFormat tells if the format is one of Formats
+func ValidFormat(format string) bool {
+	for _, known := range Formats {
+		if format == known {
+			return true
+		}
+	}
+	return false
+}
+
+// NewRenderer returns the renderer for a format. CSV is not
+// built on the commands, so it has no renderer.
+func NewRenderer(format string, brief bool, config ChronosConfig) (Renderer, error) {
+	switch format {
+	case "text":
+		calendar, err := NewWorkCalendar(config)
+		if err != nil {
+			return nil, err
+		}
+		options := TextOptions{
+			Legend:     !config.NoLegend,
+			ShortLines: config.ShortLines,
+			BaseURL:    config.Jira.URL,
+			Comments:   config.Comments,
+			Calendar:   calendar,
+		}
+		// The hours of a day are split up, so nothing is expected of a part
+		if config.GroupBy == "issue" || config.GroupBy == "project" {
+			options.Calendar = nil
+		}
+		if brief {
+			return briefRenderer{options: options}, nil
+		}
+		return textRenderer{options: options}, nil
+	case "json":
+		return jsonRenderer{}, nil
+	case "markdown":
+		return markdownRenderer{baseURL: config.Jira.URL}, nil
+	case "html":
+		return htmlRenderer{baseURL: config.Jira.URL}, nil
+	}
+	return nil, fmt.Errorf("no renderer for format %s", format)
+}
+
+// issueURL links to the issue in Jira, or nothing without a Jira URL
+func issueURL(baseURL, issue string) string {
+	if baseURL == "" {
+		return ""
+	}
+	return strings.TrimRight(baseURL, "/") + "/browse/" + issue
+}
+
+// Render prints the time entries with the renderer
+func Render(renderer Renderer, timeEntries []TimeEntry) {
+	commands := BuildCommands(timeEntries)
+	output := renderer.Render(commands)
+	fmt.Print(output.String())
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func helpRender(renderer Renderer, timeEntries []TimeEntry, goldenFilename string) (output, expected string) {
+	outputBuffer := renderer.Render(BuildCommands(timeEntries))
+	expectedBytes, _ := ioutil.ReadFile(filepath.Join("testdata", goldenFilename))
+
+	output = strings.TrimRight(outputBuffer.String(), "\n")
+	expected = strings.TrimRight(string(expectedBytes), "\n")
+
+	return
+}
+
+func TestRenderMarkdown1234(t *testing.T) {
+	renderer := markdownRenderer{baseURL: "https://myJira.atlassian.net/"}
+	output, expected := helpRender(renderer, []TimeEntry{timeEntry1, timeEntry1, timeEntry2, timeEntry3, timeEntry4}, "timeEntry11234.md")
+
+	if output != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output, expected)
+	}
+}
+
+func TestRenderHTML1234(t *testing.T) {
+	renderer := htmlRenderer{baseURL: "https://myJira.atlassian.net"}
+	output, expected := helpRender(renderer, []TimeEntry{timeEntry1, timeEntry1, timeEntry2, timeEntry3, timeEntry4}, "timeEntry11234.html")
+
+	if output != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output, expected)
+	}
+}
+
+func TestRenderMarkdownEscapes(t *testing.T) {
+	entry := timeEntry1
+	entry.Summary = "Either | or"
+
+	output := markdownRenderer{}.Render(BuildCommands([]TimeEntry{entry}))
+
+	if !strings.Contains(output.String(), "| AA-1234 | 1.00 | Either \\| or |") {
+		t.Errorf("Summary was not escaped, got:\n%s", output.String())
+	}
+}
+
+func TestRenderWorklogIDs(t *testing.T) {
+	entry := timeEntry1
+	entry.WorklogID = "10100"
+	commands := BuildCommands([]TimeEntry{entry})
+
+	markdown := markdownRenderer{}.Render(commands)
+	if !strings.Contains(markdown.String(), "| 1.00 | Summary of issue A | 10100 |") {
+		t.Errorf("Worklog ID is missing, got:\n%s", markdown.String())
+	}
+
+	html := htmlRenderer{}.Render(commands)
+	if !strings.Contains(html.String(), "<td>Summary of issue A</td><td>10100</td>") {
+		t.Errorf("Worklog ID is missing, got:\n%s", html.String())
+	}
+}
+
+func TestNewRenderer(t *testing.T) {
+	for _, format := range Formats {
+		_, err := NewRenderer(format, false, DefaultConfig())
+		if (err != nil) != (format == "csv") {
+			t.Errorf("Unexpected renderer result for %s: %v", format, err)
+		}
+	}
+
+	if _, ok := mustRenderer(t, "text", true).(briefRenderer); !ok {
+		t.Errorf("Expected the brief renderer")
+	}
+}
+
+func mustRenderer(t *testing.T, format string, brief bool) Renderer {
+	renderer, err := NewRenderer(format, brief, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Unable to create renderer %s", err)
+	}
+	return renderer
+}
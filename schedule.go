@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// Schedule represent the configured work schedule. Weekdays without
+// hours are not worked. DaysOff are holidays and vacation days, more
+// days off can be read from a local .ics calendar file.
+type Schedule struct {
+	Monday    float64  `yaml:"monday"`
+	Tuesday   float64  `yaml:"tuesday"`
+	Wednesday float64  `yaml:"wednesday"`
+	Thursday  float64  `yaml:"thursday"`
+	Friday    float64  `yaml:"friday"`
+	Saturday  float64  `yaml:"saturday"`
+	Sunday    float64  `yaml:"sunday"`
+	DaysOff   []string `yaml:"daysoff"`
+	ICS       string   `yaml:"ics"`
+}
+
+// weekdayHours returns the hours per weekday, indexed by time.Weekday
+func (s Schedule) weekdayHours() [7]float32 {
+	return [7]float32{
+		float32(s.Sunday),
+		float32(s.Monday),
+		float32(s.Tuesday),
+		float32(s.Wednesday),
+		float32(s.Thursday),
+		float32(s.Friday),
+		float32(s.Saturday),
+	}
+}
+
+// A WorkCalendar knows how many hours are expected on any day.
+// Days after Until are not expected yet, so the current week
+// is not counted as missing time before it is over. Days before
+// From are outside of the report and not expected either.
+type WorkCalendar struct {
+	hours   [7]float32
+	daysOff map[string]bool
+	From    string
+	Until   string
+}
+
+// NewWorkCalendar builds the calendar from the schedule in the config.
+// Without a schedule, HoursPerWeek is spread over Monday to Friday.
+func NewWorkCalendar(config ChronosConfig) (*WorkCalendar, error) {
+	calendar := &WorkCalendar{
+		hours:   config.Schedule.weekdayHours(),
+		daysOff: make(map[string]bool),
+		From:    config.Range.From,
+		Until:   time.Now().In(config.Location()).Format(dateLayout),
+	}
+
+	if config.Range.To != "" && config.Range.To < calendar.Until {
+		calendar.Until = config.Range.To
+	}
+
+	if calendar.hours == [7]float32{} {
+		perDay := float32(config.Jira.HoursPerWeek) / 5
+		for day := time.Monday; day <= time.Friday; day++ {
+			calendar.hours[day] = perDay
+		}
+	}
+
+	for _, day := range config.Schedule.DaysOff {
+		calendar.daysOff[day] = true
+	}
+
+	if config.Schedule.ICS != "" {
+		days, err := ReadICSDaysOff(config.Schedule.ICS)
+		if err != nil {
+			return nil, err
+		}
+		for _, day := range days {
+			calendar.daysOff[day] = true
+		}
+	}
+
+	return calendar, nil
+}
+
+// Enabled tells if any hours are expected at all
+func (c *WorkCalendar) Enabled() bool {
+	return c != nil && c.hours != [7]float32{}
+}
+
+// DayOff tells if a normal work day is a holiday or vacation day
+func (c *WorkCalendar) DayOff(date string) bool {
+	return c.daysOff[date]
+}
+
+// Expected is the number of hours expected on a date
+func (c *WorkCalendar) Expected(date string) float32 {
+	day, err := time.Parse(dateLayout, date)
+	if err != nil || c.daysOff[date] {
+		return 0
+	}
+	if c.Until != "" && date > c.Until {
+		return 0
+	}
+	if date < c.From {
+		return 0
+	}
+	return c.hours[day.Weekday()]
+}
+
+// WeekExpected is the number of hours expected in
+// the ISO week (Monday to Sunday) of the date
+func (c *WorkCalendar) WeekExpected(date string) (expected float32) {
+	day, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return 0
+	}
+
+	monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	for i := 0; i < 7; i++ {
+		expected += c.Expected(monday.AddDate(0, 0, i).Format(dateLayout))
+	}
+	return
+}
+
+// icsDate reads the date part of an ICS DTSTART/DTEND value,
+// e.g, 20261224 or 20261224T000000Z
+func icsDate(value string) (time.Time, bool) {
+	if len(value) < 8 {
+		return time.Time{}, false
+	}
+	day, err := time.Parse("20060102", value[:8])
+	return day, err == nil
+}
+
+// ReadICSDaysOff returns the days covered by the all-day events in an
+// .ics file. Events at a time of day, e.g, a meeting or a dentist
+// appointment, are not days off. Only plain events are understood,
+// recurring rules are ignored.
+func ReadICSDaysOff(icsFile string) (days []string, err error) {
+	file, err := os.Open(icsFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Long lines are folded, continuation lines start with a space or tab
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var start, end time.Time
+	var hasStart, hasEnd, allDay bool
+	for _, line := range lines {
+		colon := strings.Index(line, ":")
+		if colon < 0 {
+			continue
+		}
+		name := strings.ToUpper(strings.SplitN(line[:colon], ";", 2)[0])
+		value := line[colon+1:]
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			hasStart, hasEnd = false, false
+		case name == "DTSTART":
+			start, hasStart = icsDate(value)
+			// A date, as opposed to a date and time like 20261224T090000Z
+			allDay = !strings.Contains(value, "T")
+		case name == "DTEND":
+			end, hasEnd = icsDate(value)
+		case name == "END" && value == "VEVENT" && hasStart && allDay:
+			days = append(days, start.Format(dateLayout))
+			// DTEND is exclusive for all-day events
+			for day := start.AddDate(0, 0, 1); hasEnd && day.Before(end); day = day.AddDate(0, 0, 1) {
+				days = append(days, day.Format(dateLayout))
+			}
+		}
+	}
+	return days, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func partTimeConfig() ChronosConfig {
+	config := DefaultConfig()
+	config.Schedule = Schedule{
+		Monday:    8,
+		Tuesday:   8,
+		Wednesday: 4,
+		DaysOff:   []string{"2018-01-02"},
+	}
+	return config
+}
+
+func TestWorkCalendarPartTime(t *testing.T) {
+	calendar, err := NewWorkCalendar(partTimeConfig())
+	if err != nil {
+		t.Fatalf("Unable to create calendar %s", err)
+	}
+	calendar.Until = ""
+
+	if expected := calendar.Expected("2018-01-01"); expected != 8 {
+		t.Errorf("Wrong hours on Monday, got: %.2f, want: %.2f.", expected, 8.0)
+	}
+
+	if expected := calendar.Expected("2018-01-02"); expected != 0 || !calendar.DayOff("2018-01-02") {
+		t.Errorf("Tuesday should be a day off, got: %.2f hours.", expected)
+	}
+
+	if expected := calendar.WeekExpected("2018-01-07"); expected != 12 {
+		t.Errorf("Wrong hours for the week, got: %.2f, want: %.2f.", expected, 12.0)
+	}
+}
+
+func TestWorkCalendarUntil(t *testing.T) {
+	calendar, _ := NewWorkCalendar(partTimeConfig())
+	calendar.Until = "2018-01-01"
+
+	if expected := calendar.WeekExpected("2018-01-01"); expected != 8 {
+		t.Errorf("Days after until should not be expected, got: %.2f, want: %.2f.", expected, 8.0)
+	}
+}
+
+func TestWorkCalendarFromHoursPerWeek(t *testing.T) {
+	config := DefaultConfig()
+	config.HoursPerWeek = 40
+	calendar, _ := NewWorkCalendar(config)
+	calendar.Until = ""
+
+	if expected := calendar.WeekExpected("2018-01-03"); expected != 40 {
+		t.Errorf("Wrong hours for the week, got: %.2f, want: %.2f.", expected, 40.0)
+	}
+
+	if expected := calendar.Expected("2018-01-06"); expected != 0 {
+		t.Errorf("Saturday should not be expected, got: %.2f.", expected)
+	}
+}
+
+func TestReadICSDaysOff(t *testing.T) {
+	days, err := ReadICSDaysOff(filepath.Join("testdata", "holidays.ics"))
+	if err != nil {
+		t.Fatalf("Unable to read ics %s", err)
+	}
+
+	expected := "2018-12-24,2018-12-25,2018-12-26,2019-01-01"
+	if strings.Join(days, ",") != expected {
+		t.Errorf("Wrong days off, got: %s, want: %s.", strings.Join(days, ","), expected)
+	}
+}
+
+func TestPrettyPrintMarksDayOff(t *testing.T) {
+	calendar, _ := NewWorkCalendar(partTimeConfig())
+	calendar.Until = ""
+
+	entry := timeEntry1
+	entry.Date = "2018-01-02"
+	output := PrettyPrintWithOptions(BuildCommands([]TimeEntry{entry}), TextOptions{Calendar: calendar})
+
+	if !strings.Contains(output.String(), "2018-01-02 (day off)\n") {
+		t.Errorf("Day off was not marked, got:\n%s", output.String())
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"log"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// searchPageSize is the number of issues we ask for per request.
+// Jira Cloud caps pages at 100 regardless of what we ask for.
+const searchPageSize = 100
+
+// issueSearcher is the part of the Jira client we need to search.
+// It is satisfied by client.Issue and makes paging testable.
+type issueSearcher interface {
+	Search(jql string, options *jira.SearchOptions) ([]jira.Issue, *jira.Response, error)
+}
+
+// searchProgress is called after every page with the number of
+// issues fetched so far and the total number Jira reported
+type searchProgress func(fetched, total int)
+
+func logSearchProgress(prefix string) searchProgress {
+	return func(fetched, total int) {
+		log.Printf("[%s] Fetched %d of %d issues", prefix, fetched, total)
+	}
+}
+
+// SearchAllIssues follows StartAt/Total until all issues matching
+// the JQL are fetched. The server may return fewer issues per page
+// than requested, so we advance with what we actually got.
+func SearchAllIssues(searcher issueSearcher, jql string, options jira.SearchOptions, progress searchProgress) ([]jira.Issue, error) {
+	if options.MaxResults <= 0 {
+		options.MaxResults = searchPageSize
+	}
+
+	var issues []jira.Issue
+	for {
+		page, resp, err := searcher.Search(jql, &options)
+		if err != nil {
+			return issues, classifyError("search", resp, err)
+		}
+
+		issues = append(issues, page...)
+
+		total := len(issues)
+		if resp != nil {
+			total = resp.Total
+		}
+
+		if progress != nil {
+			progress(len(issues), total)
+		}
+
+		if len(page) == 0 || len(issues) >= total {
+			return issues, nil
+		}
+
+		options.StartAt += len(page)
+	}
+}
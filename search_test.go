@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// fakeSearcher serves issues in pages of at most pageCap,
+// just like Jira Cloud ignores a too large MaxResults
+type fakeSearcher struct {
+	issues  []jira.Issue
+	pageCap int
+	calls   int
+	failAt  int
+}
+
+func (f *fakeSearcher) Search(jql string, options *jira.SearchOptions) ([]jira.Issue, *jira.Response, error) {
+	f.calls++
+	if f.failAt > 0 && f.calls == f.failAt {
+		return nil, nil, errors.New("search failed")
+	}
+
+	size := options.MaxResults
+	if size > f.pageCap {
+		size = f.pageCap
+	}
+
+	start := options.StartAt
+	end := start + size
+	if start > len(f.issues) {
+		start = len(f.issues)
+	}
+	if end > len(f.issues) {
+		end = len(f.issues)
+	}
+
+	resp := &jira.Response{StartAt: options.StartAt, MaxResults: size, Total: len(f.issues)}
+	return f.issues[start:end], resp, nil
+}
+
+func fakeIssues(n int) (issues []jira.Issue) {
+	for i := 0; i < n; i++ {
+		issues = append(issues, jira.Issue{Key: fmt.Sprintf("AA-%d", i)})
+	}
+	return
+}
+
+func TestSearchAllIssuesFollowsPages(t *testing.T) {
+	searcher := &fakeSearcher{issues: fakeIssues(250), pageCap: 100}
+
+	var progress []int
+	issues, err := SearchAllIssues(searcher, "", jira.SearchOptions{MaxResults: 1000}, func(fetched, total int) {
+		progress = append(progress, fetched)
+	})
+
+	if err != nil {
+		t.Errorf("Unexpected error %s", err)
+	}
+
+	if len(issues) != 250 {
+		t.Errorf("Wrong number of issues, got: %d, want: %d.", len(issues), 250)
+	}
+
+	if issues[249].Key != "AA-249" {
+		t.Errorf("Wrong last issue, got: %s, want: %s.", issues[249].Key, "AA-249")
+	}
+
+	if searcher.calls != 3 || len(progress) != 3 {
+		t.Errorf("Wrong number of pages, got: %d, want: %d.", searcher.calls, 3)
+	}
+}
+
+func TestSearchAllIssuesEmpty(t *testing.T) {
+	searcher := &fakeSearcher{pageCap: 100}
+
+	issues, err := SearchAllIssues(searcher, "", jira.SearchOptions{}, nil)
+
+	if err != nil {
+		t.Errorf("Unexpected error %s", err)
+	}
+
+	if len(issues) != 0 || searcher.calls != 1 {
+		t.Errorf("Expected one call and no issues, got: %d calls, %d issues.", searcher.calls, len(issues))
+	}
+}
+
+func TestSearchAllIssuesError(t *testing.T) {
+	searcher := &fakeSearcher{issues: fakeIssues(250), pageCap: 100, failAt: 2}
+
+	issues, err := SearchAllIssues(searcher, "", jira.SearchOptions{}, nil)
+
+	if err == nil {
+		t.Errorf("Expected an error")
+	}
+
+	if len(issues) != 100 {
+		t.Errorf("Wrong number of issues before failure, got: %d, want: %d.", len(issues), 100)
+	}
+}
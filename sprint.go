@@ -1,18 +1,15 @@
 package main
 
 import (
-	"fmt"
-	"log"
-	"strings"
-
 	"github.com/andygrunwald/go-jira"
 )
 
 // SprintIssue represent a issue in the sprint
 type SprintIssue struct {
-	issue    string
-	summary  string
-	assignee string
+	issue      string
+	summary    string
+	assignee   string
+	assigneeID string
 }
 
 const unassignedIssue = "Unassigned"
@@ -23,6 +20,7 @@ func sprintIssueFromJiraIssue(issue jira.Issue) (ret SprintIssue) {
 	ret.assignee = unassignedIssue
 	if issue.Fields.Assignee != nil {
 		ret.assignee = issue.Fields.Assignee.EmailAddress
+		ret.assigneeID = issue.Fields.Assignee.AccountID
 	}
 	return
 }
@@ -39,7 +37,10 @@ func unassigned(issue SprintIssue) bool {
 }
 
 func usersIssue(issue SprintIssue, config ChronosConfig) bool {
-	return issue.assignee == config.Jira.Username || strings.HasPrefix(issue.assignee, config.Jira.Username)
+	if config.Jira.AccountID != "" {
+		return issue.assigneeID == config.Jira.AccountID
+	}
+	return sameUser(config.Jira.Username, "", "", issue.assignee)
 }
 
 func keepUsersAndUnassignedIssues(sprintIssues []SprintIssue, config ChronosConfig) (ret []SprintIssue) {
@@ -53,18 +54,14 @@ func keepUsersAndUnassignedIssues(sprintIssues []SprintIssue, config ChronosConf
 
 // UsersIssuesInOpenSprints returns all the user's issues in the open sprints
 func UsersIssuesInOpenSprints(client *jira.Client, config ChronosConfig) ([]SprintIssue, error) {
-	searchOpts := &jira.SearchOptions{
-		StartAt:    0,
-		MaxResults: 1000,
-		Expand:     "worklog",
-		Fields:     []string{"key", "summary", "worklog", "assignee"},
+	searchOpts := jira.SearchOptions{
+		Expand: "worklog",
+		Fields: []string{"key", "summary", "worklog", "assignee"},
 	}
 
-	searchStringTemplate := "resolution = Unresolved AND sprint in openSprints()"
-	searchString := fmt.Sprintf(searchStringTemplate)
-	jiraIssues, _, err := client.Issue.Search(searchString, searchOpts)
+	searchString := "resolution = Unresolved AND sprint in openSprints() AND (assignee = currentUser() OR assignee is EMPTY)"
+	jiraIssues, err := SearchAllIssues(client.Issue, searchString, searchOpts, logSearchProgress("sprint"))
 	if err != nil {
-		log.Fatalf("[sprint] Search failed %s", err)
 		return []SprintIssue{}, err
 	}
 
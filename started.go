@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultStart is when work on another day started, if no time is given
+const defaultStart = 9 * time.Hour
+
+var (
+	// daysAgo is a relative date, e.g, -2d
+	daysAgo = regexp.MustCompile(`^-(\d+)d$`)
+	// clockTime is a time of the day, e.g, 09:00
+	clockTime = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+)
+
+// ParseWorkDate reads the day work was done, either a date like
+// 2026-09-01, today, yesterday, a weekday like mon or monday, which
+// is the last such day up to today, or a number of days ago like -2d.
+// The date is midnight in the time zone of now.
+func ParseWorkDate(text string, now time.Time) (time.Time, error) {
+	today := midnight(now)
+	text = strings.ToLower(strings.TrimSpace(text))
+
+	switch text {
+	case "", "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+
+	if match := daysAgo.FindStringSubmatch(text); match != nil {
+		days, _ := strconv.Atoi(match[1])
+		return today.AddDate(0, 0, -days), nil
+	}
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if text == name || text == name[:3] {
+			back := (int(today.Weekday()) - int(day) + 7) % 7
+			return today.AddDate(0, 0, -back), nil
+		}
+	}
+
+	date, err := time.ParseInLocation(dateLayout, text, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to read the date %q, use e.g, 2026-09-01, yesterday, mon or -2d", text)
+	}
+	return date, nil
+}
+
+// parseClockTime reads a time of the day like 09:00 as the time since midnight
+func parseClockTime(text string) (time.Duration, error) {
+	match := clockTime.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return 0, fmt.Errorf("unable to read the start %q, use e.g, 09:00", text)
+	}
+	return clock(match[1], match[2])
+}
+
+// WorkStarted is when the work to log started. Without a date or a
+// start it is zero, and JIRA uses the time the work is logged. Work
+// in the future is refused unless forced, it is most likely a typo.
+func WorkStarted(date, start string, spent TimeSpent, now time.Time, force bool) (time.Time, error) {
+	if date == "" && start == "" && !spent.IsRange {
+		return time.Time{}, nil
+	}
+
+	day, err := ParseWorkDate(date, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	started := atClock(day, defaultStart)
+	switch {
+	case spent.IsRange && start != "":
+		return time.Time{}, fmt.Errorf("use either a range with --time or --start")
+	case spent.IsRange:
+		started = atClock(day, spent.From)
+	case start != "":
+		clock, err := parseClockTime(start)
+		if err != nil {
+			return time.Time{}, err
+		}
+		started = atClock(day, clock)
+	case day.Equal(midnight(now)):
+		// Today without a start, the work was done up until now
+		started = now.Add(-spent.Duration)
+		if started.Before(day) {
+			started = day
+		}
+	}
+
+	if started.After(now) && !force {
+		return time.Time{}, fmt.Errorf("%s is in the future, use --force to log it anyway", started.Format("2006-01-02 15:04"))
+	}
+	return started, nil
+}
+
+// EditStarted is the new start of an edited worklog. A start or a
+// range without a date keeps the day the worklog is on, instead of
+// moving it to today like when logging new work.
+func EditStarted(date, start string, spent TimeSpent, current, now time.Time, force bool) (time.Time, error) {
+	if date == "" && (start != "" || spent.IsRange) {
+		date = current.In(now.Location()).Format(dateLayout)
+	}
+	return WorkStarted(date, start, spent, now, force)
+}
+
+// atClock is the time of the day on the clock of day. It is built
+// from the date, as adding to midnight is off by an hour on days when
+// daylight saving time starts or ends.
+func atClock(day time.Time, clock time.Duration) time.Time {
+	hours := int(clock / time.Hour)
+	minutes := int(clock % time.Hour / time.Minute)
+	return time.Date(day.Year(), day.Month(), day.Day(), hours, minutes, 0, 0, day.Location())
+}
+
+// midnight is the start of the day of now, in the time zone of now
+func midnight(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
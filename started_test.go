@@ -0,0 +1,150 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// 2026-10-14 is a Wednesday
+var startedNow = time.Date(2026, 10, 14, 15, 0, 0, 0, time.UTC)
+
+func TestParseWorkDate(t *testing.T) {
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"", "2026-10-14"},
+		{"today", "2026-10-14"},
+		{"yesterday", "2026-10-13"},
+		{"-2d", "2026-10-12"},
+		{"mon", "2026-10-12"},
+		{"Monday", "2026-10-12"},
+		{"wed", "2026-10-14"},
+		{"thu", "2026-10-08"},
+		{"2026-09-01", "2026-09-01"},
+	}
+
+	for _, c := range cases {
+		date, err := ParseWorkDate(c.text, startedNow)
+		if err != nil {
+			t.Fatalf("Unable to parse %q: %s", c.text, err)
+		}
+		if got := date.Format(dateLayout); got != c.want {
+			t.Errorf("Wrong date for %q, got: %s, want: %s.", c.text, got, c.want)
+		}
+	}
+
+	if _, err := ParseWorkDate("1/9/2026", startedNow); err == nil {
+		t.Errorf("Bad dates should fail")
+	}
+}
+
+func TestWorkStarted(t *testing.T) {
+	hour := TimeSpent{Duration: time.Hour}
+	cases := []struct {
+		date, start string
+		spent       TimeSpent
+		want        time.Time
+	}{
+		{"", "", hour, time.Time{}},
+		{"yesterday", "", hour, time.Date(2026, 10, 13, 9, 0, 0, 0, time.UTC)},
+		{"yesterday", "13:30", hour, time.Date(2026, 10, 13, 13, 30, 0, 0, time.UTC)},
+		{"", "10:00", hour, time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)},
+		{"today", "", hour, time.Date(2026, 10, 14, 14, 0, 0, 0, time.UTC)},
+		{"mon", "", TimeSpent{Duration: time.Hour, From: 8 * time.Hour, IsRange: true}, time.Date(2026, 10, 12, 8, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		started, err := WorkStarted(c.date, c.start, c.spent, startedNow, false)
+		if err != nil {
+			t.Fatalf("Unable to get start of %q %q: %s", c.date, c.start, err)
+		}
+		if !started.Equal(c.want) {
+			t.Errorf("Wrong start of %q %q, got: %s, want: %s.", c.date, c.start, started, c.want)
+		}
+	}
+}
+
+func TestWorkStartedFuture(t *testing.T) {
+	hour := TimeSpent{Duration: time.Hour}
+	if _, err := WorkStarted("2026-10-20", "", hour, startedNow, false); err == nil {
+		t.Errorf("Future dates should fail")
+	}
+	if _, err := WorkStarted("", "16:00", hour, startedNow, false); err == nil {
+		t.Errorf("Future starts should fail")
+	}
+	if _, err := WorkStarted("2026-10-20", "", hour, startedNow, true); err != nil {
+		t.Errorf("Forced future dates should not fail: %s", err)
+	}
+}
+
+func TestWorkStartedErrors(t *testing.T) {
+	spent := TimeSpent{Duration: time.Hour, From: 8 * time.Hour, IsRange: true}
+	if _, err := WorkStarted("", "09:00", spent, startedNow, false); err == nil {
+		t.Errorf("A range and a start should fail")
+	}
+	if _, err := WorkStarted("", "9am", TimeSpent{Duration: time.Hour}, startedNow, false); err == nil {
+		t.Errorf("Bad starts should fail")
+	}
+}
+
+func TestEditStarted(t *testing.T) {
+	current := time.Date(2026, 10, 5, 14, 0, 0, 0, time.UTC)
+	hour := TimeSpent{Duration: time.Hour}
+	morning := TimeSpent{Duration: 90 * time.Minute, From: 9 * time.Hour, IsRange: true}
+	cases := []struct {
+		date, start string
+		spent       TimeSpent
+		want        time.Time
+	}{
+		// Only the time spent or the comment changes
+		{"", "", hour, time.Time{}},
+		// A range or a start keeps the day of the worklog
+		{"", "", morning, time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC)},
+		{"", "16:00", hour, time.Date(2026, 10, 5, 16, 0, 0, 0, time.UTC)},
+		{"yesterday", "", morning, time.Date(2026, 10, 13, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		started, err := EditStarted(c.date, c.start, c.spent, current, startedNow, false)
+		if err != nil {
+			t.Fatalf("Unable to get start of %q %q: %s", c.date, c.start, err)
+		}
+		if !started.Equal(c.want) {
+			t.Errorf("Wrong start of %q %q, got: %s, want: %s.", c.date, c.start, started, c.want)
+		}
+	}
+}
+
+func TestWorkStartedDaylightSaving(t *testing.T) {
+	stockholm, err := time.LoadLocation("Europe/Stockholm")
+	if err != nil {
+		t.Skipf("No time zone data %s", err)
+	}
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, stockholm)
+	hour := TimeSpent{Duration: time.Hour}
+
+	// Summer time starts at 02:00 on 2026-03-29
+	started, err := WorkStarted("2026-03-29", "09:00", hour, now, false)
+	if err != nil {
+		t.Fatalf("Unable to get start %s", err)
+	}
+	if want := time.Date(2026, 3, 29, 9, 0, 0, 0, stockholm); !started.Equal(want) {
+		t.Errorf("Wrong start, got: %s, want: %s.", started, want)
+	}
+
+	spent, _ := ParseTimeSpent("01:00-04:00", 8)
+	started, _ = WorkStarted("2026-03-29", "", spent, now, false)
+	if started.Hour() != 1 {
+		t.Errorf("Wrong start of range, got: %s.", started)
+	}
+	if duration := spent.On(started); duration != 2*time.Hour {
+		t.Errorf("Wrong range across summer time, got: %s, want: %s.", duration, 2*time.Hour)
+	}
+
+	spent, _ = ParseTimeSpent("09:00-10:30", 8)
+	started, _ = WorkStarted("2026-03-29", "", spent, now, false)
+	if started.Hour() != 9 || spent.On(started) != 90*time.Minute {
+		t.Errorf("Wrong range after summer time started, got: %s for %s.", started, spent.On(started))
+	}
+}
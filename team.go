@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// groupPageSize is how many group members we ask for at a time
+const groupPageSize = 50
+
+// groupMemberGetter is the part of the Jira client we need to list a group
+type groupMemberGetter interface {
+	GetWithOptions(name string, options *jira.GroupSearchOptions) ([]jira.GroupMember, *jira.Response, error)
+}
+
+// memberName is how a group member is known in worklogs. Jira Cloud
+// has no user names, so we fall back to the mail or the account ID.
+func memberName(member jira.GroupMember) string {
+	switch {
+	case member.Name != "":
+		return member.Name
+	case member.EmailAddress != "":
+		return member.EmailAddress
+	}
+	return member.AccountID
+}
+
+// GroupMembers lists the active users in a Jira group
+func GroupMembers(getter groupMemberGetter, group string) (users []string, err error) {
+	options := &jira.GroupSearchOptions{MaxResults: groupPageSize}
+	for {
+		members, resp, err := getter.GetWithOptions(group, options)
+		if err != nil {
+			return nil, classifyError(fmt.Sprintf("group %s", group), resp, err)
+		}
+
+		for _, member := range members {
+			users = append(users, memberName(member))
+		}
+
+		if len(members) < groupPageSize {
+			return users, nil
+		}
+		options.StartAt += len(members)
+	}
+}
+
+// ParseUsers splits a comma separated list of users
+func ParseUsers(list string) (users []string) {
+	for _, user := range strings.Split(list, ",") {
+		if user = strings.TrimSpace(user); user != "" {
+			users = append(users, user)
+		}
+	}
+	return
+}
+
+// UniqueUsers drops users listed more than once, e.g, both with
+// --users and in a team, so nobody is counted twice in a report
+func UniqueUsers(users []string) (unique []string) {
+	seen := make(map[string]bool)
+	for _, user := range users {
+		if !seen[user] {
+			seen[user] = true
+			unique = append(unique, user)
+		}
+	}
+	return
+}
+
+// ReportUsers are the users the report is about. Without
+// a team, that is only the user in the config.
+func (c ChronosConfig) ReportUsers() []string {
+	if len(c.Users) > 0 {
+		return c.Users
+	}
+	return []string{c.Jira.Username}
+}
+
+// IsTeam tells if the report is about more than one user
+func (c ChronosConfig) IsTeam() bool {
+	return len(c.ReportUsers()) > 1
+}
+
+// timeEntryUser returns the report user a time entry belongs to
+func timeEntryUser(entry TimeEntry, config ChronosConfig) (string, bool) {
+	// Our own worklogs are matched exactly once we know our account
+	if len(config.Users) == 0 && config.Jira.AccountID != "" {
+		return config.Jira.Username, entry.AuthorAccountID == config.Jira.AccountID
+	}
+
+	for _, user := range config.ReportUsers() {
+		if sameUser(user, entry.AuthorAccountID, entry.Employee, entry.EmailAddress) {
+			return user, true
+		}
+	}
+	return "", false
+}
+
+// PrettyPrintTeamBrief only prints the hours per person
+func PrettyPrintTeamBrief(timeEntries []TimeEntry, config ChronosConfig) (out bytes.Buffer) {
+	out.WriteString("===========================\n")
+	out.WriteString("Team\n")
+	out.WriteString("===========================\n")
+	out.WriteString("\n")
+
+	userOf := func(entry TimeEntry) string {
+		user, _ := timeEntryUser(entry, config)
+		return user
+	}
+	writeBreakdown(&out, "People", Breakdown(timeEntries, userOf))
+	return
+}
+
+// PrettyPrintTeam prints the hours per person, and a
+// matrix with the hours of each person on each day
+func PrettyPrintTeam(timeEntries []TimeEntry, config ChronosConfig) (out bytes.Buffer) {
+	users := UniqueUsers(config.ReportUsers())
+	out = PrettyPrintTeamBrief(timeEntries, config)
+
+	userOf := func(entry TimeEntry) string {
+		user, _ := timeEntryUser(entry, config)
+		return user
+	}
+
+	hours := make(map[string]map[string]float32)
+	userTotals := make(map[string]float32)
+	var total float32 = 0.0
+	var dates []string
+	for _, entry := range timeEntries {
+		if hours[entry.Date] == nil {
+			hours[entry.Date] = make(map[string]float32)
+			dates = append(dates, entry.Date)
+		}
+		user := userOf(entry)
+		hours[entry.Date][user] += entry.Hours
+		userTotals[user] += entry.Hours
+		total += entry.Hours
+	}
+	sort.Strings(dates)
+
+	widths := make([]int, len(users))
+	out.WriteString(fmt.Sprintf("%-10s", "Date"))
+	for i, user := range users {
+		widths[i] = len(user)
+		if widths[i] < 6 {
+			widths[i] = 6
+		}
+		out.WriteString(fmt.Sprintf(" %*s", widths[i], user))
+	}
+	out.WriteString(fmt.Sprintf(" %6s\n", "Total"))
+
+	for _, date := range dates {
+		var dateTotal float32 = 0.0
+		out.WriteString(date)
+		for i, user := range users {
+			dateTotal += hours[date][user]
+			out.WriteString(fmt.Sprintf(" %*.2f", widths[i], hours[date][user]))
+		}
+		out.WriteString(fmt.Sprintf(" %6.2f\n", dateTotal))
+	}
+
+	out.WriteString(fmt.Sprintf("%-10s", "Total"))
+	for i, user := range users {
+		out.WriteString(fmt.Sprintf(" %*.2f", widths[i], userTotals[user]))
+	}
+	out.WriteString(fmt.Sprintf(" %6.2f\n", total))
+	return
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+type fakeGroup struct {
+	members []jira.GroupMember
+	calls   int
+}
+
+func (f *fakeGroup) GetWithOptions(name string, options *jira.GroupSearchOptions) ([]jira.GroupMember, *jira.Response, error) {
+	f.calls++
+	end := options.StartAt + options.MaxResults
+	if end > len(f.members) {
+		end = len(f.members)
+	}
+	return f.members[options.StartAt:end], nil, nil
+}
+
+func TestGroupMembersPaging(t *testing.T) {
+	group := &fakeGroup{}
+	for i := 0; i < groupPageSize+2; i++ {
+		group.members = append(group.members, jira.GroupMember{Name: fmt.Sprintf("user%d", i)})
+	}
+
+	users, err := GroupMembers(group, "developers")
+	if err != nil {
+		t.Fatalf("Unable to get members %s", err)
+	}
+
+	if len(users) != groupPageSize+2 || group.calls != 2 {
+		t.Errorf("Wrong paging, got: %d users in %d calls, want: %d users in %d calls.", len(users), group.calls, groupPageSize+2, 2)
+	}
+}
+
+func TestMemberName(t *testing.T) {
+	if name := memberName(jira.GroupMember{EmailAddress: "alice@example.com", AccountID: "123"}); name != "alice@example.com" {
+		t.Errorf("Wrong name, got: %s, want: %s.", name, "alice@example.com")
+	}
+	if name := memberName(jira.GroupMember{AccountID: "123"}); name != "123" {
+		t.Errorf("Wrong name, got: %s, want: %s.", name, "123")
+	}
+}
+
+func TestParseUsers(t *testing.T) {
+	users := ParseUsers("alice, bob,,")
+	if strings.Join(users, "|") != "alice|bob" {
+		t.Errorf("Wrong users, got: %q.", users)
+	}
+}
+
+func TestReportUsers(t *testing.T) {
+	config := DefaultConfig()
+	if users := config.ReportUsers(); len(users) != 1 || users[0] != DefaultUsername || config.IsTeam() {
+		t.Errorf("Without a team the report is about the user, got: %q.", users)
+	}
+
+	config.Users = []string{"alice", "bob"}
+	if !config.IsTeam() {
+		t.Errorf("Two users should be a team")
+	}
+}
+
+func TestWorklogJQLTeam(t *testing.T) {
+	config := DefaultConfig()
+	config.Users = []string{"alice", "bob@example.com"}
+
+	jql := worklogJQL(config, DateRange{From: "2026-09-01"})
+	expected := `worklogDate >= 2026-09-01 && worklogAuthor in (alice, "bob@example.com")`
+	if jql != expected {
+		t.Errorf("Wrong JQL, got: %s, want: %s.", jql, expected)
+	}
+}
+
+func teamEntries() []TimeEntry {
+	alice := timeEntry1
+	alice.Employee = "alice"
+
+	bob := timeEntry2
+	bob.Employee = ""
+	bob.EmailAddress = "bob@example.com"
+
+	later := timeEntry3
+	later.Employee = "alice"
+
+	return []TimeEntry{alice, bob, later}
+}
+
+func TestUsersTimeEntryTeam(t *testing.T) {
+	config := DefaultConfig()
+	config.Users = []string{"alice", "bob"}
+
+	for _, entry := range teamEntries() {
+		if !usersTimeEntry(entry, config) {
+			t.Errorf("Entry of %s%s should be in the team", entry.Employee, entry.EmailAddress)
+		}
+	}
+
+	if usersTimeEntry(timeEntry4, config) {
+		t.Errorf("Entry of %s should not be in the team", timeEntry4.Employee)
+	}
+}
+
+func TestPrettyPrintTeam(t *testing.T) {
+	config := DefaultConfig()
+	config.Users = []string{"alice", "bob", "carol"}
+	output := PrettyPrintTeam(teamEntries(), config)
+
+	expected := "===========================\n" +
+		"Team\n" +
+		"===========================\n" +
+		"\n" +
+		"People\n" +
+		"\talice:   4.00  66.7%\n" +
+		"\tbob:     2.00  33.3%\n" +
+		"\n" +
+		"Date        alice    bob  carol  Total\n" +
+		"2018-01-01   1.00   2.00   0.00   3.00\n" +
+		"2018-01-08   3.00   0.00   0.00   3.00\n" +
+		"Total        4.00   2.00   0.00   6.00\n"
+	if output.String() != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output.String(), expected)
+	}
+}
+
+func TestPrettyPrintTeamBrief(t *testing.T) {
+	config := DefaultConfig()
+	config.Users = []string{"alice", "bob", "carol"}
+	output := PrettyPrintTeamBrief(teamEntries(), config)
+
+	expected := "===========================\n" +
+		"Team\n" +
+		"===========================\n" +
+		"\n" +
+		"People\n" +
+		"\talice:   4.00  66.7%\n" +
+		"\tbob:     2.00  33.3%\n" +
+		"\n"
+	if output.String() != expected {
+		t.Errorf("Wrong output, got:\n%s\nexprected:\n%s\n", output.String(), expected)
+	}
+}
+
+func TestUniqueUsers(t *testing.T) {
+	users := UniqueUsers([]string{"alice", "bob", "alice", "carol", "bob"})
+	if strings.Join(users, ",") != "alice,bob,carol" {
+		t.Errorf("Wrong users, got: %v, want: [alice bob carol].", users)
+	}
+}
+
+func TestPrettyPrintTeamDuplicateUsers(t *testing.T) {
+	config := DefaultConfig()
+	config.Users = []string{"alice", "bob", "carol"}
+	expected := PrettyPrintTeam(teamEntries(), config)
+
+	config.Users = []string{"alice", "bob", "alice", "carol"}
+	output := PrettyPrintTeam(teamEntries(), config)
+	if output.String() != expected.String() {
+		t.Errorf("Duplicate users should be counted once, got:\n%s\nexprected:\n%s\n", output.String(), expected.String())
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// A Timer is work in progress, started with chronos start. It is kept
+// in a file so it keeps running when chronos does not, e.g, over a reboot.
+type Timer struct {
+	Issue   string    `json:"issue"`
+	Comment string    `json:"comment"`
+	Started time.Time `json:"started"`
+}
+
+// worklogFunc logs work, either in JIRA or in the offline queue
+type worklogFunc func(issue string, hours, minutes int, comment string, started time.Time) error
+
+// TimerFile is where the running timer is kept
+func TimerFile() (string, error) {
+	return chronosFile("timer.json")
+}
+
+// LoadTimer reads the running timer, nil when no timer is running
+func LoadTimer(timerFile string) (timer *Timer, err error) {
+	err = readJSONFile(timerFile, &timer)
+	return timer, err
+}
+
+// SaveTimer writes the running timer, nil clears it
+func SaveTimer(timerFile string, timer *Timer) error {
+	return writeJSONFile(timerFile, timer)
+}
+
+// RoundDuration rounds to the nearest multiple of roundTo, or to
+// the nearest minute without it, since JIRA counts in minutes
+func RoundDuration(duration, roundTo time.Duration) time.Duration {
+	if roundTo < time.Minute {
+		roundTo = time.Minute
+	}
+	return (duration + roundTo/2) / roundTo * roundTo
+}
+
+// RoundTo is how timers are rounded before they are logged
+func (c ChronosConfig) RoundTo() time.Duration {
+	return time.Duration(c.Jira.TimerRounding) * time.Minute
+}
+
+// StartTimer starts a timer on an issue. A timer already running on
+// another issue is stopped and logged first, and returned as previous.
+func StartTimer(timerFile, issue, comment string, now time.Time, roundTo time.Duration, logWork worklogFunc) (previous *Timer, spent time.Duration, err error) {
+	running, err := LoadTimer(timerFile)
+	if err != nil {
+		return nil, 0, err
+	}
+	if running != nil && running.Issue == issue {
+		return nil, 0, fmt.Errorf("a timer is already running on %s since %s", issue, running.Started.Format("15:04"))
+	}
+
+	if running != nil {
+		previous, spent, err = StopTimer(timerFile, "", now, roundTo, logWork)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	err = SaveTimer(timerFile, &Timer{Issue: issue, Comment: comment, Started: now})
+	return previous, spent, err
+}
+
+// StopTimer logs the running timer and clears it. The comment, when
+// given, replaces the one from the start. If logging fails, the timer
+// keeps running so no work is lost.
+func StopTimer(timerFile, comment string, now time.Time, roundTo time.Duration, logWork worklogFunc) (*Timer, time.Duration, error) {
+	timer, err := LoadTimer(timerFile)
+	if err != nil {
+		return nil, 0, err
+	}
+	if timer == nil {
+		return nil, 0, fmt.Errorf("no timer is running, start one with chronos start ISSUE")
+	}
+
+	if comment != "" {
+		timer.Comment = comment
+	}
+
+	spent := RoundDuration(now.Sub(timer.Started), roundTo)
+	if spent > 0 {
+		hours, minutes := splitDuration(spent)
+		err = logWork(timer.Issue, hours, minutes, timer.Comment, timer.Started)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return timer, spent, SaveTimer(timerFile, nil)
+}
+
+// TimerStatus describes the running timer
+func TimerStatus(timer *Timer, now time.Time) string {
+	if timer == nil {
+		return "No timer running\n"
+	}
+
+	hours, minutes := splitDuration(now.Sub(timer.Started))
+	status := fmt.Sprintf("%s: %dh %dm since %s\n", timer.Issue, hours, minutes, timer.Started.Format("2006-01-02 15:04"))
+	if timer.Comment != "" {
+		status += fmt.Sprintf("\t// %s\n", timer.Comment)
+	}
+	return status
+}
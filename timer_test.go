@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type loggedWork struct {
+	issue   string
+	hours   int
+	minutes int
+	started time.Time
+}
+
+func testTimerFile() string {
+	timerFile := filepath.Join(os.TempDir(), "chronos-timer", "timer.json")
+	os.Remove(timerFile)
+	return timerFile
+}
+
+func recordWork(logged *[]loggedWork) worklogFunc {
+	return func(issue string, hours, minutes int, comment string, started time.Time) error {
+		*logged = append(*logged, loggedWork{issue, hours, minutes, started})
+		return nil
+	}
+}
+
+func TestRoundDuration(t *testing.T) {
+	cases := []struct {
+		duration, roundTo, want time.Duration
+	}{
+		{62 * time.Minute, 0, 62 * time.Minute},
+		{62*time.Minute + 40*time.Second, 0, 63 * time.Minute},
+		{62 * time.Minute, 15 * time.Minute, 60 * time.Minute},
+		{68 * time.Minute, 15 * time.Minute, 75 * time.Minute},
+		{20 * time.Second, 0, 0},
+	}
+
+	for _, c := range cases {
+		if got := RoundDuration(c.duration, c.roundTo); got != c.want {
+			t.Errorf("Wrong rounding of %s to %s, got: %s, want: %s.", c.duration, c.roundTo, got, c.want)
+		}
+	}
+}
+
+func TestStartAndStopTimer(t *testing.T) {
+	file := testTimerFile()
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	var logged []loggedWork
+
+	if _, _, err := StartTimer(file, "AA-1234", "", start, 0, recordWork(&logged)); err != nil {
+		t.Fatalf("Unable to start timer: %s", err)
+	}
+
+	timer, err := LoadTimer(file)
+	if err != nil || timer == nil || timer.Issue != "AA-1234" || !timer.Started.Equal(start) {
+		t.Fatalf("Timer was not saved, got: %v %s", timer, err)
+	}
+
+	stopped, spent, err := StopTimer(file, "", start.Add(90*time.Minute), 0, recordWork(&logged))
+	if err != nil {
+		t.Fatalf("Unable to stop timer: %s", err)
+	}
+	if stopped.Issue != "AA-1234" || spent != 90*time.Minute {
+		t.Errorf("Wrong stopped timer, got: %s %s.", stopped.Issue, spent)
+	}
+	if len(logged) != 1 || logged[0] != (loggedWork{"AA-1234", 1, 30, start}) {
+		t.Errorf("Wrong logged work, got: %v.", logged)
+	}
+
+	if timer, _ := LoadTimer(file); timer != nil {
+		t.Errorf("Timer should be cleared after stop, got: %v.", timer)
+	}
+	if _, _, err := StopTimer(file, "", start, 0, recordWork(&logged)); err == nil {
+		t.Errorf("Stopping without a timer should fail")
+	}
+}
+
+func TestStartTimerSwitchesIssue(t *testing.T) {
+	file := testTimerFile()
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	var logged []loggedWork
+
+	StartTimer(file, "AA-1234", "", start, 0, recordWork(&logged))
+	if _, _, err := StartTimer(file, "AA-1234", "", start, 0, recordWork(&logged)); err == nil {
+		t.Errorf("Starting the same issue twice should fail")
+	}
+
+	previous, spent, err := StartTimer(file, "AA-1235", "", start.Add(time.Hour), 0, recordWork(&logged))
+	if err != nil {
+		t.Fatalf("Unable to switch timer: %s", err)
+	}
+	if previous.Issue != "AA-1234" || spent != time.Hour || len(logged) != 1 {
+		t.Errorf("The previous timer should be logged, got: %v %s %v.", previous, spent, logged)
+	}
+
+	timer, _ := LoadTimer(file)
+	if timer.Issue != "AA-1235" {
+		t.Errorf("Wrong running timer, got: %s, want: AA-1235.", timer.Issue)
+	}
+}
+
+func TestStopTimerKeepsRunningOnError(t *testing.T) {
+	file := testTimerFile()
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	var logged []loggedWork
+	StartTimer(file, "AA-1234", "", start, 0, recordWork(&logged))
+
+	failing := func(issue string, hours, minutes int, comment string, started time.Time) error {
+		return fmt.Errorf("offline")
+	}
+	if _, _, err := StopTimer(file, "", start.Add(time.Hour), 0, failing); err == nil {
+		t.Errorf("Stop should fail when the work is not logged")
+	}
+	if timer, _ := LoadTimer(file); timer == nil {
+		t.Errorf("The timer should keep running when the work is not logged")
+	}
+}
+
+func TestTimerStatus(t *testing.T) {
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	timer := &Timer{Issue: "AA-1234", Comment: "Review", Started: start}
+
+	want := "AA-1234: 1h 5m since 2026-10-14 09:00\n\t// Review\n"
+	if got := TimerStatus(timer, start.Add(65*time.Minute)); got != want {
+		t.Errorf("Wrong status, got: %q, want: %q.", got, want)
+	}
+	if got := TimerStatus(nil, start); got != "No timer running\n" {
+		t.Errorf("Wrong status without timer, got: %q.", got)
+	}
+}
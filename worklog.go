@@ -1,21 +1,140 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/andygrunwald/go-jira"
 )
 
-func logWorkInJIRA(client *jira.Client, config ChronosConfig, issue string, hours, minutes int, comment string) error {
+// logWorkInJIRA adds a worklog to an issue. A zero started
+// time lets Jira use the time of the request.
+func logWorkInJIRA(client *jira.Client, config ChronosConfig, issue string, hours, minutes int, comment string, started time.Time) error {
 	timeString := fmt.Sprintf("%dh %dm", hours, minutes)
 	record := &jira.WorklogRecord{
 		TimeSpent: timeString,
 		Comment:   comment,
 	}
-	_, _, err := client.Issue.AddWorklogRecord(issue, record)
+	if !started.IsZero() {
+		stamp := jira.Time(started)
+		record.Started = &stamp
+	}
+	_, resp, err := client.Issue.AddWorklogRecord(issue, record)
+	if err != nil {
+		return classifyError(fmt.Sprintf("log work on %s", issue), resp, err)
+	}
+
+	return nil
+}
+
+// ListWorklogs returns the worklogs of everyone on an issue
+func ListWorklogs(client *jira.Client, config ChronosConfig, issue string) (timeEntries []TimeEntry, err error) {
+	records, resp, err := jiraWorklogs{client}.Worklogs(context.Background(), issue)
+	if err != nil {
+		return nil, classifyError(fmt.Sprintf("worklogs of %s", issue), resp, err)
+	}
+
+	for _, record := range records {
+		timeEntries = append(timeEntries, issueAndWorklogToTimeEntry(jira.Issue{Key: issue, Fields: &jira.IssueFields{}}, record, config))
+	}
+	return
+}
+
+// PrettyPrintWorklogs lists worklogs with their IDs, which
+// are needed to edit or delete them
+func PrettyPrintWorklogs(timeEntries []TimeEntry) (out bytes.Buffer) {
+	if len(timeEntries) == 0 {
+		out.WriteString("No worklogs\n")
+		return
+	}
+
+	var total float32 = 0.0
+	for _, entry := range timeEntries {
+		total += entry.Hours
+		out.WriteString(fmt.Sprintf("%8s  %s %6.2f  %s\n", entry.WorklogID, entry.Started.Format("2006-01-02 15:04"), entry.Hours, timeEntryAuthor(entry)))
+		if entry.Comment != "" {
+			out.WriteString(fmt.Sprintf("\t// %s\n", entry.Comment))
+		}
+	}
+	out.WriteString("\t------------------\n")
+	out.WriteString(fmt.Sprintf("\tTotal: %6.2f\n", total))
+	return
+}
+
+// FindWorklog finds the issue a worklog is on and when it started.
+// The worklog cache knows it for our own worklogs, otherwise we ask Jira.
+func FindWorklog(client *jira.Client, config ChronosConfig, worklogID string) (issue string, started time.Time, err error) {
+	if cacheFile, err := CacheFile(); err == nil {
+		if cache, err := LoadCache(cacheFile); err == nil {
+			if entry, ok := cache.Entries[worklogID]; ok {
+				return entry.Issue, entry.Started, nil
+			}
+		}
+	}
+
+	id, err := strconv.Atoi(worklogID)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%s is not a worklog ID", worklogID)
+	}
+
+	records, err := jiraWorklogChanges{client: client, config: config}.List([]int{id})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if len(records) == 0 {
+		return "", time.Time{}, &JiraError{Kind: NotFoundError, Op: fmt.Sprintf("worklog %s", worklogID), Err: fmt.Errorf("no such worklog")}
+	}
+	return records[0].IssueID, worklogTime(records[0], config), nil
+}
+
+// editWorklogInJIRA changes the time, comment or start of a worklog.
+// Only what is given is changed, zero values are left as they are.
+func editWorklogInJIRA(client *jira.Client, issue, worklogID string, hours, minutes int, comment string, started time.Time) error {
+	record := &jira.WorklogRecord{Comment: comment}
+	if hours > 0 || minutes > 0 {
+		record.TimeSpent = fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	if !started.IsZero() {
+		stamp := jira.Time(started)
+		record.Started = &stamp
+	}
+
+	_, resp, err := client.Issue.UpdateWorklogRecord(issue, worklogID, record)
+	if err != nil {
+		return classifyError(fmt.Sprintf("edit worklog %s", worklogID), resp, err)
+	}
+	return nil
+}
+
+// ConfirmDelete asks before a worklog is deleted, since a deleted
+// worklog can not be brought back. Only yes or y deletes it.
+func ConfirmDelete(in io.Reader, out io.Writer, issue, worklogID string) bool {
+	fmt.Fprintf(out, "Delete worklog %s on %s? [y/N] ", worklogID, issue)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// deleteWorklogInJIRA removes a worklog. go-jira has no call for
+// it, so we make the request ourselves.
+func deleteWorklogInJIRA(client *jira.Client, issue, worklogID string) error {
+	req, err := client.NewRequest("DELETE", fmt.Sprintf("rest/api/2/issue/%s/worklog/%s", issue, worklogID), nil)
 	if err != nil {
 		return err
 	}
 
+	resp, err := client.Do(req, nil)
+	if err != nil {
+		return classifyError(fmt.Sprintf("delete worklog %s", worklogID), resp, err)
+	}
 	return nil
 }
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+type worklogRequest struct {
+	method string
+	path   string
+	body   map[string]interface{}
+}
+
+func worklogServer(response string, requests *[]worklogRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := worklogRequest{method: r.Method, path: r.URL.Path}
+		if raw, _ := ioutil.ReadAll(r.Body); len(raw) > 0 {
+			json.Unmarshal(raw, &request.body)
+		}
+		*requests = append(*requests, request)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(response))
+	}))
+}
+
+func TestEditWorklogInJIRA(t *testing.T) {
+	var requests []worklogRequest
+	server := worklogServer(`{"id": "10100"}`, &requests)
+	defer server.Close()
+	client, _ := jira.NewClient(nil, server.URL)
+
+	err := editWorklogInJIRA(client, "AA-1", "10100", 2, 0, "", time.Time{})
+	if err != nil {
+		t.Fatalf("Unable to edit worklog %s", err)
+	}
+
+	request := requests[0]
+	if request.method != "PUT" || request.path != "/rest/api/2/issue/AA-1/worklog/10100" {
+		t.Errorf("Wrong request, got: %s %s.", request.method, request.path)
+	}
+	if request.body["timeSpent"] != "2h 0m" {
+		t.Errorf("Wrong time, got: %v, want: 2h 0m.", request.body["timeSpent"])
+	}
+	if _, ok := request.body["comment"]; ok {
+		t.Errorf("The comment should be left as it is, got: %v.", request.body["comment"])
+	}
+	if _, ok := request.body["started"]; ok {
+		t.Errorf("The start should be left as it is, got: %v.", request.body["started"])
+	}
+}
+
+func TestDeleteWorklogInJIRA(t *testing.T) {
+	var requests []worklogRequest
+	server := worklogServer("", &requests)
+	defer server.Close()
+	client, _ := jira.NewClient(nil, server.URL)
+
+	err := deleteWorklogInJIRA(client, "AA-1", "10100")
+	if err != nil {
+		t.Fatalf("Unable to delete worklog %s", err)
+	}
+	if len(requests) != 1 || requests[0].method != "DELETE" || requests[0].path != "/rest/api/2/issue/AA-1/worklog/10100" {
+		t.Errorf("Wrong request, got: %+v.", requests)
+	}
+}
+
+func TestDeleteWorklogNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	client, _ := jira.NewClient(nil, server.URL)
+
+	err := deleteWorklogInJIRA(client, "AA-1", "10100")
+	if errorKind(err) != NotFoundError {
+		t.Errorf("Wrong error, got: %v, want: not found.", err)
+	}
+}
+
+func TestListWorklogs(t *testing.T) {
+	var requests []worklogRequest
+	server := worklogServer(`{"worklogs": [
+		{"id": "10100", "issueId": "100", "timeSpentSeconds": 5400, "comment": "Review",
+		 "started": "2026-10-12T09:00:00.000+0000", "author": {"name": "alice"}}
+	]}`, &requests)
+	defer server.Close()
+	client, _ := jira.NewClient(nil, server.URL)
+
+	config := DefaultConfig()
+	config.Jira.TimeZone = "UTC"
+	timeEntries, err := ListWorklogs(client, config, "AA-1")
+	if err != nil {
+		t.Fatalf("Unable to list worklogs %s", err)
+	}
+
+	out := PrettyPrintWorklogs(timeEntries)
+	want := "   10100  2026-10-12 09:00   1.50  alice\n" +
+		"\t// Review\n" +
+		"\t------------------\n" +
+		"\tTotal:   1.50\n"
+	if out.String() != want {
+		t.Errorf("Wrong worklogs, got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestFindWorklogAsksJira(t *testing.T) {
+	var requests []worklogRequest
+	server := worklogServer(`[{"id": "987654321", "issueId": "100", "started": "2026-10-12T09:00:00.000+0000"}]`, &requests)
+	defer server.Close()
+	client, _ := jira.NewClient(nil, server.URL)
+
+	config := DefaultConfig()
+	config.Jira.TimeZone = "UTC"
+	issue, started, err := FindWorklog(client, config, "987654321")
+	if err != nil {
+		t.Fatalf("Unable to find worklog %s", err)
+	}
+	if issue != "100" {
+		t.Errorf("Wrong issue, got: %s, want: 100.", issue)
+	}
+	if started.Format("2006-01-02 15:04") != "2026-10-12 09:00" {
+		t.Errorf("Wrong start, got: %s, want: 2026-10-12 09:00.", started)
+	}
+
+	if _, _, err := FindWorklog(client, DefaultConfig(), "AA-1"); err == nil {
+		t.Errorf("Bad worklog IDs should fail")
+	}
+}
+
+func TestConfirmDelete(t *testing.T) {
+	answers := map[string]bool{"y\n": true, "Yes\n": true, "n\n": false, "\n": false, "": false, "maybe\n": false}
+
+	for answer, want := range answers {
+		var out bytes.Buffer
+		got := ConfirmDelete(strings.NewReader(answer), &out, "AA-1234", "10100")
+		if got != want {
+			t.Errorf("Wrong answer to %q, got: %t, want: %t.", answer, got, want)
+		}
+		if out.String() != "Delete worklog 10100 on AA-1234? [y/N] " {
+			t.Errorf("Wrong question, got: %q.", out.String())
+		}
+	}
+}